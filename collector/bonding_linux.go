@@ -0,0 +1,95 @@
+package collector
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	bondingSubsystem = "bonding"
+)
+
+type bondingCollector struct {
+	slaves, active *prometheus.Desc
+}
+
+// NewBondingCollector returns a new Collector exposing bonding interface
+// status.
+func NewBondingCollector() (Collector, error) {
+	return &bondingCollector{
+		slaves: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, bondingSubsystem, "slaves"),
+			"Number of configured slaves per bonding interface.",
+			[]string{"master"}, nil,
+		),
+		active: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, bondingSubsystem, "active"),
+			"Number of active slaves per bonding interface.",
+			[]string{"master"}, nil,
+		),
+	}, nil
+}
+
+func (c *bondingCollector) Update(ch chan<- prometheus.Metric) error {
+	bondingStats, err := readBondingStats(sysFilePath("class/net"))
+	if err != nil {
+		return err
+	}
+	if len(bondingStats) == 0 {
+		return ErrNoData
+	}
+	for master, status := range bondingStats {
+		ch <- prometheus.MustNewConstMetric(c.slaves, prometheus.GaugeValue, float64(status[0]), master)
+		ch <- prometheus.MustNewConstMetric(c.active, prometheus.GaugeValue, float64(status[1]), master)
+	}
+	return nil
+}
+
+// readBondingStats returns the number of configured and active slaves of
+// every bonding master found under root.
+func readBondingStats(root string) (status map[string][2]int, err error) {
+	masters, err := filepath.Glob(filepath.Join(root, "*", "bonding", "slaves"))
+	if err != nil {
+		return nil, err
+	}
+	status = map[string][2]int{}
+	for _, slavesPath := range masters {
+		master := filepath.Base(filepath.Dir(filepath.Dir(slavesPath)))
+		slaves, err := readStringFromFile(slavesPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				// The bond was removed while we were looking at it.
+				continue
+			}
+			return nil, fmt.Errorf("couldn't read slaves of %s: %w", master, err)
+		}
+		sstat := [2]int{0, 0}
+		for _, slave := range strings.Fields(slaves) {
+			sstat[0]++
+			if bondingSlaveActive(root, master, slave) {
+				sstat[1]++
+			}
+		}
+		status[master] = sstat
+	}
+	return status, nil
+}
+
+// bondingSlaveActive reports whether both the MII status and the operstate
+// of a slave are up. Slaves whose state can't be read count as inactive.
+func bondingSlaveActive(root, master, slave string) bool {
+	miiStatus, err := readStringFromFile(filepath.Join(root, master, "lower_"+slave, "bonding_slave", "mii_status"))
+	if os.IsNotExist(err) {
+		// Older kernels don't have the lower_<slave> links.
+		miiStatus, err = readStringFromFile(filepath.Join(root, slave, "bonding_slave", "mii_status"))
+	}
+	if err != nil || miiStatus != "up" {
+		return false
+	}
+	operstate, err := readStringFromFile(filepath.Join(root, slave, "operstate"))
+	return err == nil && operstate == "up"
+}
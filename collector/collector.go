@@ -2,6 +2,7 @@ package collector
 
 import (
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -40,29 +41,95 @@ func init() {
 	collectorState["mem"] = true
 	collectorState["disk"] = true
 	collectorState["netio"] = true
+	collectorState["netstat"] = false
+	collectorState["sockstat"] = false
+	collectorState["conntrack"] = false
+	collectorState["netclass"] = false
+	collectorState["bonding"] = false
+	collectorState["softnet"] = false
+	collectorState["processes"] = false
+	collectorState["procgroups"] = false
+	collectorState["proctotals"] = false
+	collectorState["hwmon"] = false
+	collectorState["cpufreq"] = false
+	collectorState["textfile"] = false
+	collectorState["exec"] = false
+	collectorState["hostinfo"] = false
+	collectorState["timex"] = false
+	collectorState["interrupts"] = false
+	collectorState["memdetail"] = false
+	collectorState["memfrag"] = false
+	collectorState["schedstat"] = false
+	collectorState["nfs"] = false
+	collectorState["dirsize"] = false
+	collectorState["filecheck"] = false
 
 	registerCollector("cpu", NewCPUCollector)
 	registerCollector("mem", NewMeminfoCollector)
 	registerCollector("disk", NewFilesystemCollector)
 	registerCollector("netio", NewNetDevCollector)
+	registerCollector("netstat", NewNetStatCollector)
+	registerCollector("sockstat", NewSockStatCollector)
+	registerCollector("conntrack", NewConntrackCollector)
+	registerCollector("netclass", NewNetClassCollector)
+	registerCollector("bonding", NewBondingCollector)
+	registerCollector("softnet", NewSoftnetCollector)
+	registerCollector("processes", NewProcessesCollector)
+	registerCollector("procgroups", NewProcGroupsCollector)
+	registerCollector("proctotals", NewProcTotalsCollector)
+	registerCollector("hwmon", NewHwmonCollector)
+	registerCollector("cpufreq", NewCPUFreqCollector)
+	registerCollector("textfile", NewTextFileCollector)
+	registerCollector("exec", NewExecCollector)
+	registerCollector("hostinfo", NewHostInfoCollector)
+	registerCollector("timex", NewTimexCollector)
+	registerCollector("interrupts", NewInterruptsCollector)
+	registerCollector("memdetail", NewMemDetailCollector)
+	registerCollector("memfrag", NewMemFragCollector)
+	registerCollector("schedstat", NewSchedstatCollector)
+	registerCollector("nfs", NewNFSCollector)
+	registerCollector("dirsize", NewDirSizeCollector)
+	registerCollector("filecheck", NewFileCheckCollector)
+}
+
+// SetCollectorState explicitly enables or disables the named collector. It
+// must be called before the first call to GetInstance.
+func SetCollectorState(name string, enabled bool) error {
+	if _, ok := factories[name]; !ok {
+		return fmt.Errorf("unknown collector: %s", name)
+	}
+	collectorState[name] = enabled
+	forcedCollectors[name] = true
+	return nil
 }
 
 type NodeCollector struct {
 	Collectors map[string]Collector
 }
 
+// NewNodeCollector creates the enabled collectors. It returns nil if any of
+// them can't be created, e.g. because of invalid configuration.
 func NewNodeCollector() *NodeCollector {
+	n, err := newNodeCollector()
+	if err != nil {
+		return nil
+	}
+	return n
+}
+
+// newNodeCollector is NewNodeCollector, returning which collector failed.
+func newNodeCollector() (*NodeCollector, error) {
 	collectors := make(map[string]Collector)
 	for key, enabled := range collectorState {
 		if enabled {
 			collector, err := factories[key]()
 			if err != nil {
-				return nil
+				return nil, fmt.Errorf("couldn't create collector %s: %w", key, err)
 			}
 			collectors[key] = collector
 		}
 	}
-	return &NodeCollector{Collectors: collectors}
+	return &NodeCollector{Collectors: collectors}, nil
 }
 
 func (n NodeCollector) Describe(ch chan<- *prometheus.Desc) {
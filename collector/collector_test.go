@@ -0,0 +1,65 @@
+package collector
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+var descFQNameRE = regexp.MustCompile(`fqName: "([^"]+)"`)
+
+// collectMetrics runs an update of c and returns the values of the metrics
+// keyed by name and labels, e.g. `propush_x{a="b",c="d"}`.
+func collectMetrics(t *testing.T, c Collector) map[string]float64 {
+	t.Helper()
+	ch := make(chan prometheus.Metric)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- c.Update(ch)
+		close(ch)
+	}()
+
+	metrics := map[string]float64{}
+	for m := range ch {
+		var metric dto.Metric
+		if err := m.Write(&metric); err != nil {
+			t.Fatal(err)
+		}
+		name := descFQNameRE.FindStringSubmatch(m.Desc().String())[1]
+		var labels []string
+		for _, l := range metric.GetLabel() {
+			labels = append(labels, l.GetName()+`="`+l.GetValue()+`"`)
+		}
+		sort.Strings(labels)
+		if len(labels) > 0 {
+			name += "{" + strings.Join(labels, ",") + "}"
+		}
+		switch {
+		case metric.Gauge != nil:
+			metrics[name] = metric.GetGauge().GetValue()
+		case metric.Counter != nil:
+			metrics[name] = metric.GetCounter().GetValue()
+		default:
+			metrics[name] = metric.GetUntyped().GetValue()
+		}
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("Update failed: %s", err)
+	}
+	return metrics
+}
+
+// withFixtures points the collectors at the fixture trees in testdata for the
+// duration of the test.
+func withFixtures(t *testing.T) {
+	t.Helper()
+	oldProcPath, oldSysPath := procPath, sysPath
+	procPath, sysPath = "testdata/proc", "testdata/sys"
+	t.Cleanup(func() {
+		procPath, sysPath = oldProcPath, oldSysPath
+	})
+}
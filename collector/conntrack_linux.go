@@ -0,0 +1,136 @@
+package collector
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	conntrackSubsystem = "conntrack"
+)
+
+type conntrackCollector struct {
+	current *prometheus.Desc
+	limit   *prometheus.Desc
+
+	// statDescs is filled lazily, as the stat fields depend on the kernel.
+	mtx       sync.Mutex
+	statDescs map[string]*prometheus.Desc
+}
+
+// NewConntrackCollector returns a new Collector exposing conntrack stats.
+func NewConntrackCollector() (Collector, error) {
+	return &conntrackCollector{
+		current: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, conntrackSubsystem, "entries"),
+			"Number of currently allocated flow entries for connection tracking.",
+			nil, nil,
+		),
+		limit: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, conntrackSubsystem, "entries_limit"),
+			"Maximum size of connection tracking table.",
+			nil, nil,
+		),
+		statDescs: map[string]*prometheus.Desc{},
+	}, nil
+}
+
+func (c *conntrackCollector) Update(ch chan<- prometheus.Metric) error {
+	value, err := readUintFromFile(procFilePath("sys/net/netfilter/nf_conntrack_count"))
+	if err != nil {
+		// nf_conntrack module is not loaded.
+		if os.IsNotExist(err) {
+			return ErrNoData
+		}
+		return err
+	}
+	ch <- prometheus.MustNewConstMetric(
+		c.current, prometheus.GaugeValue, float64(value))
+
+	value, err = readUintFromFile(procFilePath("sys/net/netfilter/nf_conntrack_max"))
+	if err != nil {
+		return err
+	}
+	ch <- prometheus.MustNewConstMetric(
+		c.limit, prometheus.GaugeValue, float64(value))
+
+	stats, err := getConntrackStats()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("couldn't get conntrack stats: %w", err)
+	}
+	for cpu, cpuStats := range stats {
+		cpuNum := strconv.Itoa(cpu)
+		for key, v := range cpuStats {
+			// entries is the global table size repeated on every line.
+			if key == "entries" {
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(c.statDesc(key), prometheus.CounterValue, float64(v), cpuNum)
+		}
+	}
+	return nil
+}
+
+func (c *conntrackCollector) statDesc(key string) *prometheus.Desc {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	desc, ok := c.statDescs[key]
+	if !ok {
+		desc = prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, conntrackSubsystem, "stat_"+key+"_total"),
+			fmt.Sprintf("Connection tracking statistic %s.", key),
+			[]string{"cpu"}, nil,
+		)
+		c.statDescs[key] = desc
+	}
+	return desc
+}
+
+func getConntrackStats() ([]map[string]uint64, error) {
+	file, err := os.Open(procFilePath("net/stat/nf_conntrack"))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parseConntrackStats(file)
+}
+
+// parseConntrackStats parses /proc/net/stat/nf_conntrack, which has a header
+// line followed by one line of hexadecimal values per CPU.
+func parseConntrackStats(r io.Reader) ([]map[string]uint64, error) {
+	scanner := bufio.NewScanner(r)
+	if !scanner.Scan() {
+		return nil, scanner.Err()
+	}
+	header := strings.Fields(scanner.Text())
+
+	var stats []map[string]uint64
+	for scanner.Scan() {
+		values := strings.Fields(scanner.Text())
+		if len(values) != len(header) {
+			return nil, fmt.Errorf("invalid line in nf_conntrack stats: %q", scanner.Text())
+		}
+		cpuStats := make(map[string]uint64, len(header))
+		for i, key := range header {
+			v, err := strconv.ParseUint(values[i], 16, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %s in nf_conntrack stats: %w", values[i], err)
+			}
+			cpuStats[key] = v
+		}
+		stats = append(stats, cpuStats)
+	}
+	return stats, scanner.Err()
+}
@@ -0,0 +1,58 @@
+package collector
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestConntrackCollector(t *testing.T) {
+	withFixtures(t)
+	c, err := NewConntrackCollector()
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics := collectMetrics(t, c)
+
+	for name, want := range map[string]float64{
+		`propush_conntrack_entries`:                            123,
+		`propush_conntrack_entries_limit`:                      65536,
+		`propush_conntrack_stat_invalid_total{cpu="0"}`:        5,
+		`propush_conntrack_stat_invalid_total{cpu="1"}`:        2,
+		`propush_conntrack_stat_ignore_total{cpu="0"}`:         42,
+		`propush_conntrack_stat_search_restart_total{cpu="0"}`: 3,
+		`propush_conntrack_stat_clashres_total{cpu="1"}`:       1,
+	} {
+		if got, ok := metrics[name]; !ok || got != want {
+			t.Errorf("%s = %v (present %v), want %v", name, got, ok, want)
+		}
+	}
+	if _, ok := metrics[`propush_conntrack_stat_entries_total{cpu="0"}`]; ok {
+		t.Error("entries is exported as a per-CPU stat")
+	}
+}
+
+// TestConntrackCollectorConcurrentUpdates is meant to be run with -race, and
+// checks that updates sharing the lazily built stat descs see the same ones.
+func TestConntrackCollectorConcurrentUpdates(t *testing.T) {
+	withFixtures(t)
+	c, err := NewConntrackCollector()
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := make([]map[string]float64, 4)
+	t.Run("updates", func(t *testing.T) {
+		for i := range results {
+			i := i
+			t.Run(fmt.Sprint(i), func(t *testing.T) {
+				t.Parallel()
+				results[i] = collectMetrics(t, c)
+			})
+		}
+	})
+	for i, metrics := range results[1:] {
+		if !reflect.DeepEqual(metrics, results[0]) {
+			t.Errorf("update %d got %v, want %v", i+1, metrics, results[0])
+		}
+	}
+}
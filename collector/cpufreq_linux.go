@@ -0,0 +1,103 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type cpuFreqCollector struct {
+	freq, freqMin, freqMax *prometheus.Desc
+	governor               *prometheus.Desc
+	coreThrottles          *prometheus.Desc
+	packageThrottles       *prometheus.Desc
+}
+
+// NewCPUFreqCollector returns a new Collector exposing CPU frequency,
+// scaling governor and thermal throttling stats.
+func NewCPUFreqCollector() (Collector, error) {
+	desc := func(name, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, cpuCollectorSubsystem, name),
+			help, labels, nil,
+		)
+	}
+	return &cpuFreqCollector{
+		freq:             desc("frequency_hertz", "Current CPU frequency in hertz.", "cpu"),
+		freqMin:          desc("frequency_min_hertz", "Minimum CPU frequency the governor may select in hertz.", "cpu"),
+		freqMax:          desc("frequency_max_hertz", "Maximum CPU frequency the governor may select in hertz.", "cpu"),
+		governor:         desc("scaling_governor", "Scaling governor of the CPU, value is always 1.", "cpu", "governor"),
+		coreThrottles:    desc("core_throttles_total", "Number of times the CPU core has been throttled.", "cpu"),
+		packageThrottles: desc("package_throttles_total", "Number of times the CPU package has been throttled.", "package"),
+	}, nil
+}
+
+func (c *cpuFreqCollector) Update(ch chan<- prometheus.Metric) error {
+	cpus, err := filepath.Glob(sysFilePath("devices/system/cpu/cpu[0-9]*"))
+	if err != nil {
+		return err
+	}
+
+	var found bool
+	packages := map[string]bool{}
+	for _, cpuDir := range cpus {
+		cpu := strings.TrimPrefix(filepath.Base(cpuDir), "cpu")
+
+		if c.updateFreq(ch, filepath.Join(cpuDir, "cpufreq"), cpu) {
+			found = true
+		}
+
+		throttleDir := filepath.Join(cpuDir, "thermal_throttle")
+		if count, err := readUintFromFile(filepath.Join(throttleDir, "core_throttle_count")); err == nil {
+			found = true
+			ch <- prometheus.MustNewConstMetric(c.coreThrottles, prometheus.CounterValue, float64(count), cpu)
+		}
+		// The package counter is repeated for every CPU of the package.
+		pkg, err := readStringFromFile(filepath.Join(cpuDir, "topology", "physical_package_id"))
+		if err != nil || packages[pkg] {
+			continue
+		}
+		if count, err := readUintFromFile(filepath.Join(throttleDir, "package_throttle_count")); err == nil {
+			found = true
+			packages[pkg] = true
+			ch <- prometheus.MustNewConstMetric(c.packageThrottles, prometheus.CounterValue, float64(count), pkg)
+		}
+	}
+	if !found {
+		return ErrNoData
+	}
+	return nil
+}
+
+// updateFreq sends the frequencies and governor from the cpufreq directory
+// of a CPU, and reports whether it exists.
+func (c *cpuFreqCollector) updateFreq(ch chan<- prometheus.Metric, dir, cpu string) bool {
+	if _, err := os.Stat(dir); err != nil {
+		return false
+	}
+	// The frequencies are in kHz. cpuinfo_cur_freq is the frequency read from
+	// the hardware, but it is only readable by root.
+	for _, f := range []struct {
+		desc  *prometheus.Desc
+		files []string
+	}{
+		{c.freq, []string{"cpuinfo_cur_freq", "scaling_cur_freq"}},
+		{c.freqMin, []string{"scaling_min_freq"}},
+		{c.freqMax, []string{"scaling_max_freq"}},
+	} {
+		for _, file := range f.files {
+			value, err := readUintFromFile(filepath.Join(dir, file))
+			if err != nil {
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(f.desc, prometheus.GaugeValue, float64(value)*1000, cpu)
+			break
+		}
+	}
+	if governor, err := readStringFromFile(filepath.Join(dir, "scaling_governor")); err == nil {
+		ch <- prometheus.MustNewConstMetric(c.governor, prometheus.GaugeValue, 1, cpu, governor)
+	}
+	return true
+}
@@ -0,0 +1,304 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	dirSizeSubsystem = "dirsize"
+)
+
+// DirSizePath is a directory walked by the dirsize collector.
+type DirSizePath struct {
+	Path string
+	// MaxDepth is how many levels below Path are walked, 0 means no limit.
+	// It defaults to DirSizeMaxDepth.
+	MaxDepth int
+	// Timeout bounds the duration of a walk, which then reports what it found
+	// so far. It defaults to DirSizeTimeout.
+	Timeout time.Duration
+}
+
+var (
+	// DirSizePaths is the list of directories walked by the dirsize collector.
+	DirSizePaths []DirSizePath
+	// DirSizeMaxDepth is the depth limit of paths which don't set one.
+	DirSizeMaxDepth int
+	// DirSizeTimeout is the walk timeout of paths which don't set one.
+	DirSizeTimeout = 30 * time.Second
+	// DirSizeInterval is how often the directories are walked. The walks run
+	// in the background, so every update exposes the results of the last one.
+	DirSizeInterval = 5 * time.Minute
+)
+
+var (
+	dirSizeBytesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, dirSizeSubsystem, "bytes"),
+		"Total size of the regular files in the directory.",
+		[]string{"path"}, nil,
+	)
+	dirSizeFilesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, dirSizeSubsystem, "files"),
+		"Number of regular files in the directory.",
+		[]string{"path"}, nil,
+	)
+	dirSizeDirectoriesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, dirSizeSubsystem, "directories"),
+		"Number of subdirectories in the directory.",
+		[]string{"path"}, nil,
+	)
+	dirSizeOldestDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, dirSizeSubsystem, "oldest_mtime_seconds"),
+		"Unixtime mtime of the oldest regular file in the directory.",
+		[]string{"path"}, nil,
+	)
+	dirSizeNewestDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, dirSizeSubsystem, "newest_mtime_seconds"),
+		"Unixtime mtime of the newest regular file in the directory.",
+		[]string{"path"}, nil,
+	)
+	dirSizeDurationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, dirSizeSubsystem, "walk_duration_seconds"),
+		"Duration of the last walk of the directory.",
+		[]string{"path"}, nil,
+	)
+	dirSizeTimestampDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, dirSizeSubsystem, "walk_timestamp_seconds"),
+		"Unixtime the last walk of the directory finished.",
+		[]string{"path"}, nil,
+	)
+	dirSizeTimeoutDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, dirSizeSubsystem, "timeout"),
+		"1 if the last walk was stopped by its timeout, 0 otherwise.",
+		[]string{"path"}, nil,
+	)
+	dirSizeErrorDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, dirSizeSubsystem, "scrape_error"),
+		"1 if there was an error reading the directory or some of its entries, 0 otherwise.",
+		[]string{"path"}, nil,
+	)
+
+	errDirSizeTimeout = errors.New("dirsize walk timed out")
+)
+
+// dirSizeResult is the outcome of walking a directory.
+type dirSizeResult struct {
+	bytes, files, directories uint64
+	oldest, newest            time.Time
+	duration                  time.Duration
+	finished                  time.Time
+	timedOut                  bool
+	// err is the first error hit by the walk, which is incomplete then. The
+	// sizes are only valid if the path itself could be read.
+	err      error
+	pathRead bool
+}
+
+type dirSizeCollector struct {
+	paths    []DirSizePath
+	interval time.Duration
+	start    sync.Once
+
+	mtx     sync.Mutex
+	results map[string]dirSizeResult
+}
+
+// NewDirSizeCollector returns a new Collector exposing the size, file count
+// and file ages of directories, walked periodically in the background from
+// the first update on.
+func NewDirSizeCollector() (Collector, error) {
+	if DirSizeInterval <= 0 {
+		return nil, errors.New("dirsize interval must be positive")
+	}
+	paths := make([]DirSizePath, 0, len(DirSizePaths))
+	seen := map[string]bool{}
+	for _, path := range DirSizePaths {
+		if path.Path == "" {
+			return nil, errors.New("dirsize path without path")
+		}
+		path.Path = filepath.Clean(path.Path)
+		if seen[path.Path] {
+			return nil, fmt.Errorf("duplicate dirsize path: %s", path.Path)
+		}
+		seen[path.Path] = true
+		if path.MaxDepth <= 0 {
+			path.MaxDepth = DirSizeMaxDepth
+		}
+		if path.Timeout <= 0 {
+			path.Timeout = DirSizeTimeout
+		}
+		paths = append(paths, path)
+	}
+	return &dirSizeCollector{
+		paths:    paths,
+		interval: DirSizeInterval,
+		results:  map[string]dirSizeResult{},
+	}, nil
+}
+
+func (c *dirSizeCollector) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		// The paths are walked one after the other, so that at most one
+		// walk competes with the rest of the host for IO.
+		for _, path := range c.paths {
+			result := walkDirSize(path)
+			c.mtx.Lock()
+			c.results[path.Path] = result
+			c.mtx.Unlock()
+		}
+		<-ticker.C
+	}
+}
+
+func (c *dirSizeCollector) Update(ch chan<- prometheus.Metric) error {
+	if len(c.paths) == 0 {
+		return ErrNoData
+	}
+	c.start.Do(func() { go c.run(c.interval) })
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	var failed []string
+	for _, path := range c.paths {
+		// Paths which haven't been walked yet are left out rather than
+		// reported as empty.
+		result, ok := c.results[path.Path]
+		if !ok {
+			continue
+		}
+		var scrapeError float64
+		if result.err != nil {
+			scrapeError = 1
+			failed = append(failed, path.Path)
+		}
+		ch <- prometheus.MustNewConstMetric(dirSizeErrorDesc, prometheus.GaugeValue, scrapeError, path.Path)
+		ch <- prometheus.MustNewConstMetric(dirSizeDurationDesc, prometheus.GaugeValue, result.duration.Seconds(), path.Path)
+		ch <- prometheus.MustNewConstMetric(dirSizeTimestampDesc, prometheus.GaugeValue, float64(result.finished.UnixNano())/1e9, path.Path)
+		var timedOut float64
+		if result.timedOut {
+			timedOut = 1
+		}
+		ch <- prometheus.MustNewConstMetric(dirSizeTimeoutDesc, prometheus.GaugeValue, timedOut, path.Path)
+		if !result.pathRead {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(dirSizeBytesDesc, prometheus.GaugeValue, float64(result.bytes), path.Path)
+		ch <- prometheus.MustNewConstMetric(dirSizeFilesDesc, prometheus.GaugeValue, float64(result.files), path.Path)
+		ch <- prometheus.MustNewConstMetric(dirSizeDirectoriesDesc, prometheus.GaugeValue, float64(result.directories), path.Path)
+		if result.files > 0 {
+			ch <- prometheus.MustNewConstMetric(dirSizeOldestDesc, prometheus.GaugeValue, float64(result.oldest.UnixNano())/1e9, path.Path)
+			ch <- prometheus.MustNewConstMetric(dirSizeNewestDesc, prometheus.GaugeValue, float64(result.newest.UnixNano())/1e9, path.Path)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to walk some dirsize paths: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// walkDirSize walks path, summing up the regular files down to its depth
+// limit. A symlink to the directory is followed, but no symlinks below it.
+// Entries which can't be read are skipped, and the first such error is kept
+// in the result.
+func walkDirSize(path DirSizePath) (result dirSizeResult) {
+	begin := time.Now()
+	deadline := begin.Add(path.Timeout)
+	defer func() {
+		result.finished = time.Now()
+		result.duration = result.finished.Sub(begin)
+	}()
+
+	// WalkDir doesn't follow a symlinked root, which would otherwise look
+	// like an empty directory.
+	root, err := filepath.EvalSymlinks(path.Path)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	info, err := os.Stat(root)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	if !info.IsDir() {
+		result.err = fmt.Errorf("%s is not a directory", path.Path)
+		return result
+	}
+
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == root {
+				return err
+			}
+			if result.err == nil {
+				result.err = err
+			}
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errDirSizeTimeout
+		}
+		if d.IsDir() {
+			if p == root {
+				return nil
+			}
+			result.directories++
+			if path.MaxDepth > 0 && dirSizeDepth(root, p) >= path.MaxDepth {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			// The file was removed since the directory was read.
+			return nil
+		}
+		result.files++
+		result.bytes += uint64(info.Size())
+		mtime := info.ModTime()
+		if result.oldest.IsZero() || mtime.Before(result.oldest) {
+			result.oldest = mtime
+		}
+		if mtime.After(result.newest) {
+			result.newest = mtime
+		}
+		return nil
+	})
+	switch {
+	case err == errDirSizeTimeout:
+		result.timedOut = true
+		result.pathRead = true
+	case err != nil:
+		// The directory itself couldn't be read.
+		result.err = err
+	default:
+		result.pathRead = true
+	}
+	return result
+}
+
+// dirSizeDepth returns how many levels p is below root.
+func dirSizeDepth(root, p string) int {
+	rel, err := filepath.Rel(root, p)
+	if err != nil {
+		return 0
+	}
+	return strings.Count(rel, string(filepath.Separator)) + 1
+}
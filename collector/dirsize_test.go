@@ -0,0 +1,71 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWalkDirSize(t *testing.T) {
+	root := t.TempDir()
+	spool := filepath.Join(root, "data/spool")
+	old := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for file, content := range map[string]string{
+		"a":          "12345",
+		"sub/b":      "123",
+		"sub/deep/c": "1",
+	} {
+		path := filepath.Join(spool, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Chtimes(filepath.Join(spool, "a"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(root, "spool")
+	if err := os.Symlink(spool, link); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(spool, "a")
+
+	for _, tc := range []struct {
+		name         string
+		path         DirSizePath
+		bytes, files uint64
+		wantErr      bool
+	}{
+		{name: "unlimited", path: DirSizePath{Path: spool}, bytes: 9, files: 3},
+		{name: "depth limit", path: DirSizePath{Path: spool, MaxDepth: 1}, bytes: 5, files: 1},
+		{name: "symlinked root", path: DirSizePath{Path: link}, bytes: 9, files: 3},
+		{name: "missing", path: DirSizePath{Path: filepath.Join(root, "missing")}, wantErr: true},
+		{name: "not a directory", path: DirSizePath{Path: file}, wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.path.Timeout = time.Minute
+			result := walkDirSize(tc.path)
+			if tc.wantErr {
+				if result.err == nil || result.pathRead {
+					t.Fatalf("got err %v, pathRead %v; want an error", result.err, result.pathRead)
+				}
+				return
+			}
+			if result.err != nil || !result.pathRead {
+				t.Fatalf("got err %v, pathRead %v", result.err, result.pathRead)
+			}
+			if result.bytes != tc.bytes || result.files != tc.files {
+				t.Errorf("got %d bytes in %d files, want %d in %d", result.bytes, result.files, tc.bytes, tc.files)
+			}
+			if !result.oldest.Equal(old) {
+				t.Errorf("got oldest mtime %s, want %s", result.oldest, old)
+			}
+			if result.finished.IsZero() {
+				t.Error("finished time not set")
+			}
+		})
+	}
+}
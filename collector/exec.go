@@ -0,0 +1,246 @@
+package collector
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+const (
+	execSubsystem = "exec"
+	// execWaitDelay is how long Run waits for stdout to be closed after the
+	// command was killed, for children which left its process group.
+	execWaitDelay = time.Second
+)
+
+// ExecCommand is a command run by the exec collector. Its stdout is parsed as
+// the Prometheus text format, or else as lines of "<name> <value>".
+type ExecCommand struct {
+	// Name is the value of the command label of the metrics of the command.
+	Name string
+	Path string
+	Args []string
+	// Env is added to the environment of ProPush, in the form "key=value".
+	Env []string
+	// Timeout defaults to ExecTimeout.
+	Timeout time.Duration
+}
+
+var (
+	// ExecCommands is the list of commands run by the exec collector.
+	ExecCommands []ExecCommand
+	// ExecTimeout is the timeout of commands which don't set one.
+	ExecTimeout = 10 * time.Second
+)
+
+var (
+	execExitCodeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, execSubsystem, "exit_code"),
+		"Exit code of the command, -1 if it didn't exit by itself.",
+		[]string{"command"}, nil,
+	)
+	execDurationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, execSubsystem, "duration_seconds"),
+		"Duration of the command.",
+		[]string{"command"}, nil,
+	)
+	execTimeoutDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, execSubsystem, "timeout"),
+		"1 if the command was killed because of its timeout, 0 otherwise.",
+		[]string{"command"}, nil,
+	)
+	execParseErrorDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, execSubsystem, "parse_error"),
+		"1 if the output of the command couldn't be parsed, 0 otherwise.",
+		[]string{"command"}, nil,
+	)
+
+	execInvalidNameCharRE = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+)
+
+type execCollector struct {
+	commands []ExecCommand
+}
+
+// NewExecCollector returns a new Collector exposing the metrics printed by
+// external commands.
+func NewExecCollector() (Collector, error) {
+	commands := make([]ExecCommand, 0, len(ExecCommands))
+	seen := map[string]bool{}
+	for _, command := range ExecCommands {
+		if command.Name == "" || command.Path == "" {
+			return nil, errors.New("exec command without name or path")
+		}
+		if seen[command.Name] {
+			return nil, fmt.Errorf("duplicate exec command: %s", command.Name)
+		}
+		seen[command.Name] = true
+		if command.Timeout <= 0 {
+			command.Timeout = ExecTimeout
+		}
+		commands = append(commands, command)
+	}
+	return &execCollector{
+		commands: commands,
+	}, nil
+}
+
+func (c *execCollector) Update(ch chan<- prometheus.Metric) error {
+	if len(c.commands) == 0 {
+		return ErrNoData
+	}
+	var (
+		mtx    sync.Mutex
+		failed []string
+	)
+	// The commands run concurrently, in the same way NodeCollector runs the
+	// collectors, so that the slowest command bounds the duration.
+	wg := sync.WaitGroup{}
+	wg.Add(len(c.commands))
+	for _, command := range c.commands {
+		go func(command ExecCommand) {
+			defer wg.Done()
+			if err := runExecCommand(command, ch); err != nil {
+				mtx.Lock()
+				failed = append(failed, command.Name)
+				mtx.Unlock()
+			}
+		}(command)
+	}
+	wg.Wait()
+
+	if len(failed) > 0 {
+		return fmt.Errorf("exec commands failed: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func runExecCommand(command ExecCommand, ch chan<- prometheus.Metric) error {
+	ctx, cancel := context.WithTimeout(context.Background(), command.Timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, command.Path, command.Args...)
+	cmd.Env = append(os.Environ(), command.Env...)
+	// Killing only the command would leave its children running, and Run
+	// would wait for them as long as they hold stdout open.
+	setExecProcessGroup(cmd)
+	cmd.WaitDelay = execWaitDelay
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+
+	begin := time.Now()
+	runErr := cmd.Run()
+	duration := time.Since(begin)
+
+	exitCode := -1
+	if cmd.ProcessState != nil && cmd.ProcessState.Exited() {
+		exitCode = cmd.ProcessState.ExitCode()
+	}
+	var timedOut float64
+	if ctx.Err() == context.DeadlineExceeded {
+		timedOut = 1
+	}
+	ch <- prometheus.MustNewConstMetric(execExitCodeDesc, prometheus.GaugeValue, float64(exitCode), command.Name)
+	ch <- prometheus.MustNewConstMetric(execDurationDesc, prometheus.GaugeValue, duration.Seconds(), command.Name)
+	ch <- prometheus.MustNewConstMetric(execTimeoutDesc, prometheus.GaugeValue, timedOut, command.Name)
+
+	// The output of a command which failed is still used, since scripts
+	// commonly report what they found before exiting non-zero.
+	families, parseErr := parseExecOutput(stdout.Bytes())
+	var parseError float64
+	if parseErr != nil {
+		parseError = 1
+	}
+	ch <- prometheus.MustNewConstMetric(execParseErrorDesc, prometheus.GaugeValue, parseError, command.Name)
+	for _, mf := range families {
+		for _, m := range mf.Metric {
+			m.Label = append(m.Label, &dto.LabelPair{
+				Name:  stringPtr("command"),
+				Value: stringPtr(command.Name),
+			})
+		}
+		convertMetricFamily(mf, ch)
+	}
+
+	if runErr != nil {
+		return runErr
+	}
+	return parseErr
+}
+
+// parseExecOutput parses the output of a command as the Prometheus text
+// format, falling back to lines of "<name> <value>".
+func parseExecOutput(output []byte) (map[string]*dto.MetricFamily, error) {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(output))
+	if err == nil {
+		for name, mf := range families {
+			for _, m := range mf.Metric {
+				if m.TimestampMs != nil {
+					return nil, fmt.Errorf("metric %s contains unsupported client-side timestamps", name)
+				}
+				for _, l := range m.Label {
+					if l.GetName() == "command" {
+						return nil, fmt.Errorf("metric %s already contains a command label", name)
+					}
+				}
+			}
+		}
+		return families, nil
+	}
+	return parseExecSimpleOutput(output)
+}
+
+// parseExecSimpleOutput parses lines of "<name> <value>" into untyped
+// metrics, replacing characters which are invalid in metric names with "_".
+func parseExecSimpleOutput(output []byte) (map[string]*dto.MetricFamily, error) {
+	families := map[string]*dto.MetricFamily{}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid line: %q", line)
+		}
+		value, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value in line %q: %w", line, err)
+		}
+		name := execInvalidNameCharRE.ReplaceAllString(fields[0], "_")
+		if name[0] >= '0' && name[0] <= '9' {
+			name = "_" + name
+		}
+		if _, ok := families[name]; ok {
+			return nil, fmt.Errorf("duplicate metric: %s", name)
+		}
+		families[name] = &dto.MetricFamily{
+			Name: stringPtr(name),
+			Help: stringPtr("Metric read from exec output."),
+			Type: dto.MetricType_UNTYPED.Enum(),
+			Metric: []*dto.Metric{{
+				Untyped: &dto.Untyped{Value: &value},
+			}},
+		}
+	}
+	return families, scanner.Err()
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
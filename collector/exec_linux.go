@@ -0,0 +1,15 @@
+package collector
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setExecProcessGroup runs cmd in its own process group, and makes the
+// timeout kill the whole group instead of cmd only.
+func setExecProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,63 @@
+package collector
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestRunExecCommandTimeoutKillsChildren(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "child.pid")
+	command := ExecCommand{
+		Name: "sleepy",
+		Path: "/bin/sh",
+		// The child is started in the background, so killing only the shell
+		// would leave it running.
+		Args:    []string{"-c", `sleep 30 & echo $! > "$1"; wait; echo x 1`, "sh", pidFile},
+		Timeout: 500 * time.Millisecond,
+	}
+	ch := make(chan prometheus.Metric, 16)
+
+	if err := runExecCommand(command, ch); err == nil {
+		t.Error("expected an error for a command killed by its timeout")
+	}
+
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for !processGone(pid) {
+		if time.Now().After(deadline) {
+			syscall.Kill(pid, syscall.SIGKILL)
+			t.Fatalf("child %d of the command is still running after its timeout", pid)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// processGone reports whether pid exited. The killed child is reparented, so
+// it may stay a zombie if nothing reaps it.
+func processGone(pid int) bool {
+	if err := syscall.Kill(pid, 0); errors.Is(err, syscall.ESRCH) {
+		return true
+	}
+	stat, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return true
+	}
+	// The state follows the parenthesized command name.
+	fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+	return len(fields) > 0 && fields[0] == "Z"
+}
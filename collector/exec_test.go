@@ -0,0 +1,48 @@
+package collector
+
+import "testing"
+
+func TestParseExecOutput(t *testing.T) {
+	for _, tc := range []struct {
+		name, output string
+		want         []string
+		wantErr      bool
+	}{
+		{
+			name:   "text format",
+			output: "# TYPE backup_ok gauge\nbackup_ok 1\n",
+			want:   []string{"backup_ok"},
+		},
+		{
+			name:   "simple lines",
+			output: "queue_length 3\nworkers 4\n",
+			want:   []string{"queue_length", "workers"},
+		},
+		{
+			name:    "command label",
+			output:  "backup_ok{command=\"x\"} 1\n",
+			wantErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			families, err := parseExecOutput([]byte(tc.output))
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(families) != len(tc.want) {
+				t.Fatalf("got %d families, want %d", len(families), len(tc.want))
+			}
+			for _, name := range tc.want {
+				if _, ok := families[name]; !ok {
+					t.Errorf("missing family %s", name)
+				}
+			}
+		})
+	}
+}
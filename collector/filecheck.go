@@ -0,0 +1,225 @@
+package collector
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	fileCheckSubsystem = "filecheck"
+)
+
+var (
+	// FileCheckPatterns is the list of glob patterns, in the syntax of
+	// filepath.Match, of the files checked by the filecheck collector.
+	FileCheckPatterns []string
+	// FileCheckSHA256 enables counting the changes of the SHA-256 sum of
+	// the matched files. A file is only hashed again when its size or mtime
+	// changed.
+	FileCheckSHA256 bool
+)
+
+var (
+	fileCheckExistsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, fileCheckSubsystem, "exists"),
+		"1 if the pattern matches at least one regular file, 0 otherwise.",
+		[]string{"pattern"}, nil,
+	)
+	fileCheckMatchesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, fileCheckSubsystem, "matches"),
+		"Number of regular files matched by the pattern.",
+		[]string{"pattern"}, nil,
+	)
+	fileCheckNewestAgeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, fileCheckSubsystem, "newest_age_seconds"),
+		"Seconds since the mtime of the newest file matched by the pattern.",
+		[]string{"pattern"}, nil,
+	)
+	fileCheckSizeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, fileCheckSubsystem, "size_bytes"),
+		"Size of the file.",
+		[]string{"pattern", "file"}, nil,
+	)
+	fileCheckMtimeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, fileCheckSubsystem, "mtime_seconds"),
+		"Unixtime mtime of the file.",
+		[]string{"pattern", "file"}, nil,
+	)
+	fileCheckAgeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, fileCheckSubsystem, "age_seconds"),
+		"Seconds since the mtime of the file.",
+		[]string{"pattern", "file"}, nil,
+	)
+	fileCheckChangesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, fileCheckSubsystem, "sha256_changes_total"),
+		"Number of times the SHA-256 sum of the file changed since it was first seen.",
+		[]string{"pattern", "file"}, nil,
+	)
+)
+
+// fileCheckState is what is remembered of a file between updates to detect
+// changes of its content.
+type fileCheckState struct {
+	size    int64
+	mtime   time.Time
+	sum     [sha256.Size]byte
+	changes uint64
+}
+
+type fileCheckCollector struct {
+	patterns []string
+	hash     bool
+
+	mtx    sync.Mutex
+	states map[string]fileCheckState
+}
+
+// NewFileCheckCollector returns a new Collector exposing the existence, size
+// and age of the files matched by glob patterns.
+func NewFileCheckCollector() (Collector, error) {
+	seen := map[string]bool{}
+	for _, pattern := range FileCheckPatterns {
+		if pattern == "" {
+			return nil, errors.New("empty filecheck pattern")
+		}
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid filecheck pattern %q: %w", pattern, err)
+		}
+		if seen[pattern] {
+			return nil, fmt.Errorf("duplicate filecheck pattern: %s", pattern)
+		}
+		seen[pattern] = true
+	}
+	return &fileCheckCollector{
+		patterns: append([]string(nil), FileCheckPatterns...),
+		hash:     FileCheckSHA256,
+		states:   map[string]fileCheckState{},
+	}, nil
+}
+
+func (c *fileCheckCollector) Update(ch chan<- prometheus.Metric) error {
+	if len(c.patterns) == 0 {
+		return ErrNoData
+	}
+	now := time.Now()
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	var failed []string
+	current := map[string]fileCheckState{}
+	// hashFailed is the files which couldn't be hashed, so that a file matched
+	// by several patterns is only tried and reported once.
+	hashFailed := map[string]bool{}
+	for _, pattern := range c.patterns {
+		// Glob only fails on malformed patterns, which were rejected above.
+		files, _ := filepath.Glob(pattern)
+
+		var (
+			matches int
+			newest  time.Time
+		)
+		for _, file := range files {
+			info, err := os.Stat(file)
+			if err != nil || !info.Mode().IsRegular() {
+				// Files which vanished after the glob are treated as not
+				// matched, just like directories.
+				continue
+			}
+			matches++
+			mtime := info.ModTime()
+			if mtime.After(newest) {
+				newest = mtime
+			}
+			ch <- prometheus.MustNewConstMetric(fileCheckSizeDesc, prometheus.GaugeValue, float64(info.Size()), pattern, file)
+			ch <- prometheus.MustNewConstMetric(fileCheckMtimeDesc, prometheus.GaugeValue, float64(mtime.UnixNano())/1e9, pattern, file)
+			ch <- prometheus.MustNewConstMetric(fileCheckAgeDesc, prometheus.GaugeValue, now.Sub(mtime).Seconds(), pattern, file)
+
+			if !c.hash || hashFailed[file] {
+				continue
+			}
+			state, ok := current[file]
+			if !ok {
+				// A file matched by several patterns is only hashed once.
+				state, err = c.checkFile(file, info)
+				if err != nil {
+					failed = append(failed, file)
+					hashFailed[file] = true
+					// The file is remembered, so that its changes don't
+					// start over once it can be read again.
+					if prev, ok := c.states[file]; ok {
+						current[file] = prev
+					}
+					continue
+				}
+				current[file] = state
+			}
+			ch <- prometheus.MustNewConstMetric(fileCheckChangesDesc, prometheus.CounterValue, float64(state.changes), pattern, file)
+		}
+
+		var exists float64
+		if matches > 0 {
+			exists = 1
+			ch <- prometheus.MustNewConstMetric(fileCheckNewestAgeDesc, prometheus.GaugeValue, now.Sub(newest).Seconds(), pattern)
+		}
+		ch <- prometheus.MustNewConstMetric(fileCheckExistsDesc, prometheus.GaugeValue, exists, pattern)
+		ch <- prometheus.MustNewConstMetric(fileCheckMatchesDesc, prometheus.GaugeValue, float64(matches), pattern)
+	}
+	// Files which are gone are forgotten, so a file coming back starts over
+	// with no changes.
+	c.states = current
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to hash some files: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// checkFile returns the new state of file, hashing it unless its size and
+// mtime are the same as in the previous update.
+func (c *fileCheckCollector) checkFile(file string, info os.FileInfo) (fileCheckState, error) {
+	prev, seen := c.states[file]
+	if seen && prev.size == info.Size() && prev.mtime.Equal(info.ModTime()) {
+		return prev, nil
+	}
+	sum, err := sha256File(file)
+	if err != nil {
+		return fileCheckState{}, err
+	}
+	state := fileCheckState{
+		size:  info.Size(),
+		mtime: info.ModTime(),
+		sum:   sum,
+	}
+	if seen {
+		state.changes = prev.changes
+		if sum != prev.sum {
+			state.changes++
+		}
+	}
+	return state, nil
+}
+
+func sha256File(file string) (sum [sha256.Size]byte, err error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return sum, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return sum, err
+	}
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
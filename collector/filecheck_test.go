@@ -0,0 +1,67 @@
+package collector
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileCheckCollectorSHA256Changes(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"unchanged": "a",
+		"touched":   "b",
+		"changed":   "c",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	patterns, hash := FileCheckPatterns, FileCheckSHA256
+	t.Cleanup(func() { FileCheckPatterns, FileCheckSHA256 = patterns, hash })
+	all, changed := filepath.Join(dir, "*"), filepath.Join(dir, "changed")
+	FileCheckPatterns = []string{all, changed}
+	FileCheckSHA256 = true
+	c, err := NewFileCheckCollector()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The collector keeps its own patterns.
+	FileCheckPatterns[0] = filepath.Join(dir, "missing")
+
+	collectMetrics(t, c)
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "touched"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	// The size is the same, only the mtime tells that the file changed.
+	if err := os.WriteFile(filepath.Join(dir, "changed"), []byte("C"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filepath.Join(dir, "changed"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	metrics := collectMetrics(t, c)
+
+	for _, tc := range []struct {
+		pattern, file string
+		want          float64
+	}{
+		{all, "unchanged", 0},
+		{all, "touched", 0},
+		{all, "changed", 1},
+		{changed, "changed", 1},
+	} {
+		name := fmt.Sprintf(`propush_filecheck_sha256_changes_total{file=%q,pattern=%q}`, filepath.Join(dir, tc.file), tc.pattern)
+		if got, ok := metrics[name]; !ok || got != tc.want {
+			t.Errorf("%s = %v (present %v), want %v", name, got, ok, tc.want)
+		}
+	}
+	if got := metrics[fmt.Sprintf(`propush_filecheck_matches{pattern=%q}`, all)]; got != 3 {
+		t.Errorf("pattern %s matches %v files, want 3", all, got)
+	}
+}
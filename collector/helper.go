@@ -17,3 +17,11 @@ func readUintFromFile(path string) (uint64, error) {
 	}
 	return value, nil
 }
+
+func readStringFromFile(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
@@ -0,0 +1,182 @@
+package collector
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	hostInfoSubsystem = "host"
+)
+
+var (
+	hostInfoLabelNames = []string{
+		"sysname", "nodename", "release", "version", "machine",
+		"os_id", "os_name", "os_version_id",
+		"sys_vendor", "product_name", "bios_vendor", "bios_version", "bios_date",
+	}
+
+	// hostDMIFiles are the files of /sys/class/dmi/id which become labels, in
+	// the order of hostInfoLabelNames. The serial numbers are left out since
+	// they are only readable by root.
+	hostDMIFiles = []string{"sys_vendor", "product_name", "bios_vendor", "bios_version", "bios_date"}
+)
+
+type hostInfoCollector struct {
+	info     *prometheus.Desc
+	uptime   *prometheus.Desc
+	bootTime *prometheus.Desc
+}
+
+// NewHostInfoCollector returns a new Collector exposing the host identity
+// from uname, os-release and DMI, and the uptime.
+func NewHostInfoCollector() (Collector, error) {
+	return &hostInfoCollector{
+		info: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, hostInfoSubsystem, "info"),
+			"Labeled host information from uname, os-release and DMI, value is always 1.",
+			hostInfoLabelNames, nil,
+		),
+		uptime: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, hostInfoSubsystem, "uptime_seconds"),
+			"Time since the host booted in seconds.",
+			nil, nil,
+		),
+		bootTime: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, hostInfoSubsystem, "boot_time_seconds"),
+			"Boot time of the host since unix epoch in seconds.",
+			nil, nil,
+		),
+	}, nil
+}
+
+func (c *hostInfoCollector) Update(ch chan<- prometheus.Metric) error {
+	var uname unix.Utsname
+	if err := unix.Uname(&uname); err != nil {
+		return fmt.Errorf("couldn't get uname: %w", err)
+	}
+	osRelease, err := getOSRelease()
+	if err != nil {
+		return fmt.Errorf("couldn't get os-release: %w", err)
+	}
+
+	labels := []string{
+		unix.ByteSliceToString(uname.Sysname[:]),
+		unix.ByteSliceToString(uname.Nodename[:]),
+		unix.ByteSliceToString(uname.Release[:]),
+		unix.ByteSliceToString(uname.Version[:]),
+		unix.ByteSliceToString(uname.Machine[:]),
+		osRelease["ID"],
+		osRelease["PRETTY_NAME"],
+		osRelease["VERSION_ID"],
+	}
+	for _, file := range hostDMIFiles {
+		// DMI isn't available on every platform, e.g. many ARM boards.
+		value, _ := readStringFromFile(sysFilePath(filepath.Join("class/dmi/id", file)))
+		labels = append(labels, value)
+	}
+	ch <- prometheus.MustNewConstMetric(c.info, prometheus.GaugeValue, 1, labels...)
+
+	uptime, err := getUptime()
+	if err != nil {
+		return fmt.Errorf("couldn't get uptime: %w", err)
+	}
+	ch <- prometheus.MustNewConstMetric(c.uptime, prometheus.GaugeValue, uptime)
+	bootTime, err := getBootTime()
+	if err != nil {
+		return fmt.Errorf("couldn't get boot time: %w", err)
+	}
+	ch <- prometheus.MustNewConstMetric(c.bootTime, prometheus.GaugeValue, float64(bootTime))
+	return nil
+}
+
+// getBootTime returns the btime line of /proc/stat.
+func getBootTime() (uint64, error) {
+	file, err := os.Open(procFilePath("stat"))
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	// The intr line lists every IRQ and easily exceeds the default limit.
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == "btime" {
+			return strconv.ParseUint(fields[1], 10, 64)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("no btime in stat")
+}
+
+// getUptime returns the first field of /proc/uptime.
+func getUptime() (float64, error) {
+	data, err := ioutil.ReadFile(procFilePath("uptime"))
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("invalid uptime: %q", data)
+	}
+	return strconv.ParseFloat(fields[0], 64)
+}
+
+func getOSRelease() (map[string]string, error) {
+	file, err := os.Open(rootfsFilePath("etc/os-release"))
+	if os.IsNotExist(err) {
+		// os-release(5): fall back to /usr/lib/os-release.
+		file, err = os.Open(rootfsFilePath("usr/lib/os-release"))
+	}
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parseOSRelease(file)
+}
+
+// parseOSRelease parses the KEY=value lines of os-release(5), where values
+// may be enclosed in single or double quotes.
+func parseOSRelease(r io.Reader) (map[string]string, error) {
+	osRelease := map[string]string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key, value := parts[0], parts[1]
+		if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				value = unquoted
+			} else {
+				value = value[1 : len(value)-1]
+			}
+		} else if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+			value = value[1 : len(value)-1]
+		}
+		osRelease[key] = value
+	}
+	return osRelease, scanner.Err()
+}
@@ -0,0 +1,215 @@
+package collector
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	hwmonSubsystem = "hwmon"
+)
+
+var (
+	hwmonLabelNames = []string{"chip", "chip_name", "sensor", "label"}
+
+	hwmonSensorRE = regexp.MustCompile(`^(temp|fan|in|power|curr)(\d+)_(input|label|min|max|crit)$`)
+
+	// hwmonProperties are the numeric properties matched by hwmonSensorRE.
+	hwmonProperties = []string{"input", "min", "max", "crit"}
+)
+
+// hwmonUnits describes how the sysfs values of a sensor type are exposed:
+// the metric name suffix and the divisor to get to the base unit.
+var hwmonUnits = map[string]struct {
+	unit    string
+	divisor float64
+}{
+	"temp":  {"celsius", 1000},  // millidegree Celsius
+	"fan":   {"rpm", 1},         // revolutions per minute
+	"in":    {"volts", 1000},    // millivolt
+	"power": {"watts", 1000000}, // microwatt
+	"curr":  {"amps", 1000},     // milliampere
+}
+
+type hwmonCollector struct {
+	// descs is keyed by sensor kind and property, e.g. "temp_max".
+	descs           map[string]*prometheus.Desc
+	thermalZoneDesc *prometheus.Desc
+}
+
+// hwmonSensor is a sensor of a chip, e.g. temp1, with its values keyed by
+// property, e.g. "input" or "max".
+type hwmonSensor struct {
+	kind, name, label string
+	values            map[string]float64
+}
+
+// NewHwmonCollector returns a new Collector exposing hwmon sensors and
+// thermal zones.
+func NewHwmonCollector() (Collector, error) {
+	descs := map[string]*prometheus.Desc{}
+	for kind, unit := range hwmonUnits {
+		for _, property := range hwmonProperties {
+			name := kind + "_" + unit.unit
+			if property != "input" {
+				name = kind + "_" + property + "_" + unit.unit
+			}
+			descs[kind+"_"+property] = prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, hwmonSubsystem, name),
+				fmt.Sprintf("Hardware monitor %s %s in %s.", kind, property, unit.unit),
+				hwmonLabelNames, nil,
+			)
+		}
+	}
+	return &hwmonCollector{
+		descs: descs,
+		thermalZoneDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "thermal_zone", "temp_celsius"),
+			"Temperature of the thermal zone in degree Celsius.",
+			[]string{"zone", "type"}, nil,
+		),
+	}, nil
+}
+
+func (c *hwmonCollector) Update(ch chan<- prometheus.Metric) error {
+	hwmonErr := c.updateHwmon(ch)
+	thermalErr := c.updateThermalZones(ch)
+	if hwmonErr == ErrNoData && thermalErr == ErrNoData {
+		return ErrNoData
+	}
+	if hwmonErr != nil && hwmonErr != ErrNoData {
+		return hwmonErr
+	}
+	if thermalErr != nil && thermalErr != ErrNoData {
+		return thermalErr
+	}
+	return nil
+}
+
+func (c *hwmonCollector) updateHwmon(ch chan<- prometheus.Metric) error {
+	chips, err := ioutil.ReadDir(sysFilePath("class/hwmon"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ErrNoData
+		}
+		return fmt.Errorf("couldn't list hwmon chips: %w", err)
+	}
+	if len(chips) == 0 {
+		return ErrNoData
+	}
+	for _, chip := range chips {
+		dir := sysFilePath(filepath.Join("class/hwmon", chip.Name()))
+		if err := c.updateChip(ch, dir, chip.Name()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *hwmonCollector) updateChip(ch chan<- prometheus.Metric, dir, hwmonName string) error {
+	chip := hwmonChipID(dir, hwmonName)
+	// Older drivers keep the attributes in the device directory.
+	if _, err := os.Stat(filepath.Join(dir, "name")); os.IsNotExist(err) {
+		dir = filepath.Join(dir, "device")
+	}
+	chipName, _ := readStringFromFile(filepath.Join(dir, "name"))
+
+	sensors, err := readHwmonSensors(dir)
+	if err != nil {
+		return fmt.Errorf("couldn't read sensors of %s: %w", hwmonName, err)
+	}
+	for _, sensor := range sensors {
+		unit := hwmonUnits[sensor.kind]
+		for property, value := range sensor.values {
+			ch <- prometheus.MustNewConstMetric(c.descs[sensor.kind+"_"+property], prometheus.GaugeValue,
+				value/unit.divisor, chip, chipName, sensor.name, sensor.label)
+		}
+	}
+	return nil
+}
+
+// hwmonChipID returns a name of the chip which is stable across reboots,
+// derived from the device it belongs to, falling back to the hwmon name.
+func hwmonChipID(dir, hwmonName string) string {
+	device, err := filepath.EvalSymlinks(filepath.Join(dir, "device"))
+	if err != nil {
+		return hwmonName
+	}
+	return filepath.Base(device)
+}
+
+// readHwmonSensors reads the sensor attributes of a chip directory.
+func readHwmonSensors(dir string) ([]*hwmonSensor, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var sensors []*hwmonSensor
+	byName := map[string]*hwmonSensor{}
+	for _, file := range files {
+		parts := hwmonSensorRE.FindStringSubmatch(file.Name())
+		if parts == nil {
+			continue
+		}
+		kind, name, property := parts[1], parts[1]+parts[2], parts[3]
+		sensor, ok := byName[name]
+		if !ok {
+			sensor = &hwmonSensor{kind: kind, name: name, values: map[string]float64{}}
+			byName[name] = sensor
+			sensors = append(sensors, sensor)
+		}
+
+		path := filepath.Join(dir, file.Name())
+		if property == "label" {
+			sensor.label, _ = readStringFromFile(path)
+			continue
+		}
+		// Reading a sensor which is absent or faulty fails (e.g. ENODATA),
+		// such values are skipped.
+		data, err := readStringFromFile(path)
+		if err != nil {
+			continue
+		}
+		value, err := strconv.ParseFloat(data, 64)
+		if err != nil {
+			continue
+		}
+		sensor.values[property] = value
+	}
+	return sensors, nil
+}
+
+func (c *hwmonCollector) updateThermalZones(ch chan<- prometheus.Metric) error {
+	zones, err := filepath.Glob(sysFilePath("class/thermal/thermal_zone*"))
+	if err != nil {
+		return err
+	}
+	if len(zones) == 0 {
+		return ErrNoData
+	}
+	for _, zone := range zones {
+		zoneType, err := readStringFromFile(filepath.Join(zone, "type"))
+		if err != nil {
+			continue
+		}
+		// temp is in millidegree Celsius, and can be negative.
+		data, err := readStringFromFile(filepath.Join(zone, "temp"))
+		if err != nil {
+			continue
+		}
+		temp, err := strconv.ParseInt(data, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid temperature %s of %s: %w", data, zone, err)
+		}
+		ch <- prometheus.MustNewConstMetric(c.thermalZoneDesc, prometheus.GaugeValue,
+			float64(temp)/1000, strings.TrimPrefix(filepath.Base(zone), "thermal_zone"), zoneType)
+	}
+	return nil
+}
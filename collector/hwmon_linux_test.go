@@ -0,0 +1,67 @@
+package collector
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReadHwmonSensors(t *testing.T) {
+	sensors, err := readHwmonSensors("testdata/sys/class/hwmon/hwmon0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]hwmonSensor{}
+	for _, sensor := range sensors {
+		got[sensor.name] = *sensor
+	}
+	want := map[string]hwmonSensor{
+		"temp1": {kind: "temp", name: "temp1", label: "Package id 0", values: map[string]float64{"input": 45000, "max": 84000, "crit": 100000}},
+		"temp2": {kind: "temp", name: "temp2", label: "Core 0", values: map[string]float64{"input": 43000}},
+		// The value of temp3 can't be read, so only the sensor is known.
+		"temp3": {kind: "temp", name: "temp3", values: map[string]float64{}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestHwmonChipID(t *testing.T) {
+	for _, tc := range []struct {
+		dir, hwmonName, want string
+	}{
+		{"testdata/sys/class/hwmon/hwmon0", "hwmon0", "coretemp.0"},
+		{"testdata/sys/class/hwmon/hwmon1", "hwmon1", "it87.656"},
+		{"testdata/sys/class/hwmon/hwmon9", "hwmon9", "hwmon9"},
+	} {
+		if got := hwmonChipID(tc.dir, tc.hwmonName); got != tc.want {
+			t.Errorf("hwmonChipID(%q) = %q, want %q", tc.dir, got, tc.want)
+		}
+	}
+}
+
+func TestHwmonCollector(t *testing.T) {
+	withFixtures(t)
+	c, err := NewHwmonCollector()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := collectMetrics(t, c)
+
+	want := map[string]float64{
+		`propush_hwmon_temp_celsius{chip="coretemp.0",chip_name="coretemp",label="Package id 0",sensor="temp1"}`:      45,
+		`propush_hwmon_temp_max_celsius{chip="coretemp.0",chip_name="coretemp",label="Package id 0",sensor="temp1"}`:  84,
+		`propush_hwmon_temp_crit_celsius{chip="coretemp.0",chip_name="coretemp",label="Package id 0",sensor="temp1"}`: 100,
+		`propush_hwmon_temp_celsius{chip="coretemp.0",chip_name="coretemp",label="Core 0",sensor="temp2"}`:            43,
+		// it87 uses the legacy layout with the attributes in device/.
+		`propush_hwmon_temp_celsius{chip="it87.656",chip_name="it87",label="",sensor="temp1"}`: -5,
+		`propush_hwmon_fan_rpm{chip="it87.656",chip_name="it87",label="",sensor="fan1"}`:       1200,
+		`propush_hwmon_in_volts{chip="it87.656",chip_name="it87",label="",sensor="in0"}`:       1.104,
+		`propush_hwmon_power_watts{chip="it87.656",chip_name="it87",label="",sensor="power1"}`: 3.5,
+		`propush_hwmon_curr_amps{chip="it87.656",chip_name="it87",label="",sensor="curr1"}`:    0.25,
+		`propush_thermal_zone_temp_celsius{type="x86_pkg_temp",zone="0"}`:                      45,
+		`propush_thermal_zone_temp_celsius{type="acpitz",zone="1"}`:                            -2.5,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v,\nwant %v", got, want)
+	}
+}
@@ -20,23 +20,42 @@ type Instance struct {
 	instance string
 }
 
-var instance *Instance
+var (
+	instance    *Instance
+	instanceErr error
+)
 
+// GetInstance returns the Instance, creating it on the first call. It
+// returns nil if the Instance couldn't be created, see InstanceError.
 func GetInstance() *Instance {
 	if instance == nil {
-		instance = &Instance{
-			R:        prometheus.NewRegistry(),
-			C:        NewNodeCollector(),
-			job:      "defaultJobName",
-			instance: "defaultInstanceName",
-		}
-		if instance.R == nil || instance.C == nil || instance.R.Register(instance.C) != nil {
-			instance = nil
-		}
+		instance, instanceErr = newInstance()
 	}
 	return instance
 }
 
+// InstanceError returns why the last call to GetInstance returned nil.
+func InstanceError() error {
+	return instanceErr
+}
+
+func newInstance() (*Instance, error) {
+	c, err := newNodeCollector()
+	if err != nil {
+		return nil, err
+	}
+	r := prometheus.NewRegistry()
+	if err := r.Register(c); err != nil {
+		return nil, fmt.Errorf("couldn't register collectors: %w", err)
+	}
+	return &Instance{
+		R:        r,
+		C:        c,
+		job:      "defaultJobName",
+		instance: "defaultInstanceName",
+	}, nil
+}
+
 func (ins *Instance) GetMetrics() string {
 	if ins == nil {
 		return ""
@@ -83,7 +102,7 @@ func (ins *Instance) PushMetrics(gateway string, data string) error {
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
 		body, _ := ioutil.ReadAll(resp.Body)
-		errStr := fmt.Sprintf("unexpected status code %s, PushGateway url = %s, body = %s.", resp.StatusCode, url, string(body))
+		errStr := fmt.Sprintf("unexpected status code %d, PushGateway url = %s, body = %s.", resp.StatusCode, url, string(body))
 		return errors.New(errStr)
 	}
 	return nil
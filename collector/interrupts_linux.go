@@ -0,0 +1,208 @@
+package collector
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	interruptsSubsystem = "interrupts"
+)
+
+var (
+	// InterruptsInclude is a regexp of IRQs to include in the interrupts
+	// collector, matched against the IRQ (e.g. "24" or "NMI") and its
+	// devices. It is mutually exclusive to InterruptsExclude.
+	InterruptsInclude string
+	// InterruptsExclude is a regexp of IRQs to exclude from the interrupts
+	// collector. It is mutually exclusive to InterruptsInclude.
+	InterruptsExclude string
+	// InterruptsAggregateCPUs sums the interrupts of all CPUs instead of
+	// exposing them per CPU.
+	InterruptsAggregateCPUs bool
+)
+
+type interruptsCollector struct {
+	includeRE, excludeRE *regexp.Regexp
+	aggregate            bool
+	interrupts           *prometheus.Desc
+	globalInterrupts     *prometheus.Desc
+	softirqs             *prometheus.Desc
+}
+
+// interrupt is a line of /proc/interrupts.
+type interrupt struct {
+	irq, info, devices string
+	values             []uint64
+	// global is set for counts which aren't kept per CPU, e.g. ERR and MIS,
+	// whose single value is the host-wide count.
+	global bool
+}
+
+// interruptsGlobal are the lines of /proc/interrupts with a single host-wide
+// value, which can't be told apart by their values on hosts with one CPU.
+var interruptsGlobal = map[string]bool{
+	"ERR": true,
+	"MIS": true,
+	"Err": true,
+}
+
+// NewInterruptsCollector returns a new Collector exposing interrupt and
+// softirq stats.
+func NewInterruptsCollector() (Collector, error) {
+	if InterruptsInclude != "" && InterruptsExclude != "" {
+		return nil, errors.New("interrupts include & exclude are mutually exclusive")
+	}
+	c := &interruptsCollector{aggregate: InterruptsAggregateCPUs}
+	var err error
+	if InterruptsInclude != "" {
+		if c.includeRE, err = regexp.Compile(InterruptsInclude); err != nil {
+			return nil, fmt.Errorf("invalid interrupts include pattern: %w", err)
+		}
+	}
+	if InterruptsExclude != "" {
+		if c.excludeRE, err = regexp.Compile(InterruptsExclude); err != nil {
+			return nil, fmt.Errorf("invalid interrupts exclude pattern: %w", err)
+		}
+	}
+
+	interruptsLabels := []string{"irq", "type", "devices"}
+	softirqsLabels := []string{"type"}
+	if !c.aggregate {
+		interruptsLabels = append([]string{"cpu"}, interruptsLabels...)
+		softirqsLabels = append([]string{"cpu"}, softirqsLabels...)
+	}
+	c.interrupts = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, interruptsSubsystem, "total"),
+		"Number of interrupts handled.",
+		interruptsLabels, nil,
+	)
+	c.globalInterrupts = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, interruptsSubsystem, "global_total"),
+		"Number of interrupts of host-wide counters such as ERR and MIS, which aren't kept per CPU.",
+		[]string{"irq", "type"}, nil,
+	)
+	c.softirqs = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, interruptsSubsystem, "softirqs_total"),
+		"Number of softirqs handled.",
+		softirqsLabels, nil,
+	)
+	return c, nil
+}
+
+func (c *interruptsCollector) Update(ch chan<- prometheus.Metric) error {
+	cpus, interrupts, err := getInterrupts()
+	if err != nil {
+		return fmt.Errorf("couldn't get interrupts: %w", err)
+	}
+	for _, irq := range interrupts {
+		if !c.match(irq) {
+			continue
+		}
+		if irq.global {
+			ch <- prometheus.MustNewConstMetric(c.globalInterrupts, prometheus.CounterValue, float64(sumUint64(irq.values)), irq.irq, irq.info)
+			continue
+		}
+		if c.aggregate {
+			ch <- prometheus.MustNewConstMetric(c.interrupts, prometheus.CounterValue, float64(sumUint64(irq.values)), irq.irq, irq.info, irq.devices)
+			continue
+		}
+		for i, v := range irq.values {
+			ch <- prometheus.MustNewConstMetric(c.interrupts, prometheus.CounterValue, float64(v), cpus[i], irq.irq, irq.info, irq.devices)
+		}
+	}
+
+	cpus, softirqs, err := getSoftirqs()
+	if err != nil {
+		return fmt.Errorf("couldn't get softirqs: %w", err)
+	}
+	for name, values := range softirqs {
+		if c.aggregate {
+			ch <- prometheus.MustNewConstMetric(c.softirqs, prometheus.CounterValue, float64(sumUint64(values)), name)
+			continue
+		}
+		for i, v := range values {
+			ch <- prometheus.MustNewConstMetric(c.softirqs, prometheus.CounterValue, float64(v), cpus[i], name)
+		}
+	}
+	return nil
+}
+
+func (c *interruptsCollector) match(irq interrupt) bool {
+	if c.excludeRE != nil && (c.excludeRE.MatchString(irq.irq) || c.excludeRE.MatchString(irq.devices)) {
+		return false
+	}
+	if c.includeRE != nil && !c.includeRE.MatchString(irq.irq) && !c.includeRE.MatchString(irq.devices) {
+		return false
+	}
+	return true
+}
+
+func sumUint64(values []uint64) uint64 {
+	var sum uint64
+	for _, v := range values {
+		sum += v
+	}
+	return sum
+}
+
+func getInterrupts() ([]string, []interrupt, error) {
+	file, err := os.Open(procFilePath("interrupts"))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	return parseInterrupts(file)
+}
+
+// parseInterrupts parses /proc/interrupts into the CPU numbers of its columns
+// and its IRQs. Lines with fewer values than CPUs, e.g. ERR and MIS with a
+// single one, are host-wide counts and marked global.
+func parseInterrupts(r io.Reader) ([]string, []interrupt, error) {
+	scanner := bufio.NewScanner(r)
+	if !scanner.Scan() {
+		return nil, nil, errors.New("interrupts empty")
+	}
+	cpus := strings.Fields(scanner.Text())
+	for i, cpu := range cpus {
+		cpus[i] = strings.TrimPrefix(cpu, "CPU")
+	}
+
+	var interrupts []interrupt
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		irq := interrupt{irq: strings.TrimSuffix(fields[0], ":")}
+		i := 1
+		for ; i < len(fields) && i <= len(cpus); i++ {
+			v, err := strconv.ParseUint(fields[i], 10, 64)
+			if err != nil {
+				break
+			}
+			irq.values = append(irq.values, v)
+		}
+		irq.global = interruptsGlobal[irq.irq] || len(irq.values) != len(cpus)
+		rest := fields[i:]
+		if _, err := strconv.Atoi(irq.irq); err == nil && len(rest) > 0 {
+			// "IO-APIC   2-edge      timer": the chip, then the devices.
+			irq.info = rest[0]
+			irq.devices = strings.Join(rest[1:], " ")
+		} else {
+			// "Non-maskable interrupts": a description only.
+			irq.info = strings.Join(rest, " ")
+		}
+		interrupts = append(interrupts, irq)
+	}
+	return cpus, interrupts, scanner.Err()
+}
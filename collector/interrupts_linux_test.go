@@ -0,0 +1,77 @@
+package collector
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseInterrupts(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		file     string
+		wantCPUs []string
+		want     []interrupt
+	}{
+		{
+			name: "numeric, named and single-value lines",
+			file: "           CPU0       CPU1       \n" +
+				"  0:         31          0   IO-APIC   2-edge      timer\n" +
+				" 24:          1          5   PCI-MSI 65536-edge      nvme0q0, eth0\n" +
+				"NMI:          2          3   Non-maskable interrupts\n" +
+				"LOC:     123456     654321   Local timer interrupts\n" +
+				"ERR:          4\n" +
+				"MIS:          0\n",
+			wantCPUs: []string{"0", "1"},
+			want: []interrupt{
+				{irq: "0", info: "IO-APIC", devices: "2-edge timer", values: []uint64{31, 0}},
+				{irq: "24", info: "PCI-MSI", devices: "65536-edge nvme0q0, eth0", values: []uint64{1, 5}},
+				{irq: "NMI", info: "Non-maskable interrupts", values: []uint64{2, 3}},
+				{irq: "LOC", info: "Local timer interrupts", values: []uint64{123456, 654321}},
+				{irq: "ERR", values: []uint64{4}, global: true},
+				{irq: "MIS", values: []uint64{0}, global: true},
+			},
+		},
+		{
+			// With one CPU the global lines have as many values as there are
+			// CPUs, and are only told apart by their name.
+			name: "single CPU",
+			file: "           CPU0       \n" +
+				" 26:          1  IO-APIC   4-edge      ttyS0\n" +
+				"ERR:          0\n" +
+				"MIS:          0\n",
+			wantCPUs: []string{"0"},
+			want: []interrupt{
+				{irq: "26", info: "IO-APIC", devices: "4-edge ttyS0", values: []uint64{1}},
+				{irq: "ERR", values: []uint64{0}, global: true},
+				{irq: "MIS", values: []uint64{0}, global: true},
+			},
+		},
+		{
+			name: "arm",
+			file: "           CPU0       CPU1       \n" +
+				" 11:       7713       6546     GICv3  30 Level     arch_timer\n" +
+				"IPI0:        17         20       Rescheduling interrupts\n" +
+				"Err:          0\n",
+			wantCPUs: []string{"0", "1"},
+			want: []interrupt{
+				{irq: "11", info: "GICv3", devices: "30 Level arch_timer", values: []uint64{7713, 6546}},
+				{irq: "IPI0", info: "Rescheduling interrupts", values: []uint64{17, 20}},
+				{irq: "Err", values: []uint64{0}, global: true},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cpus, got, err := parseInterrupts(strings.NewReader(tc.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(cpus, tc.wantCPUs) {
+				t.Errorf("cpus = %q, want %q", cpus, tc.wantCPUs)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("interrupts = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
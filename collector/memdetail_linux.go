@@ -0,0 +1,162 @@
+package collector
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// hugepagesFiles are the per-size files of /sys/kernel/mm/hugepages exposed
+// by the memdetail collector.
+var hugepagesFiles = []struct {
+	file, name, help string
+}{
+	{"nr_hugepages", "hugepages_total", "Number of huge pages in the pool."},
+	{"free_hugepages", "hugepages_free", "Number of huge pages in the pool which are not yet allocated."},
+	{"resv_hugepages", "hugepages_reserved", "Number of huge pages reserved but not yet allocated."},
+	{"surplus_hugepages", "hugepages_surplus", "Number of huge pages in the pool above nr_hugepages."},
+	{"nr_overcommit_hugepages", "hugepages_overcommit", "Maximum number of surplus huge pages."},
+}
+
+// ksmFiles are the files of /sys/kernel/mm/ksm exposed by the memdetail
+// collector.
+var ksmFiles = []struct {
+	file, help string
+	valueType  prometheus.ValueType
+}{
+	{"run", "Whether KSM is running (0 stopped, 1 running, 2 unmerging).", prometheus.GaugeValue},
+	{"pages_shared", "Number of shared pages in use.", prometheus.GaugeValue},
+	{"pages_sharing", "Number of sites sharing the shared pages, i.e. how much is saved.", prometheus.GaugeValue},
+	{"pages_unshared", "Number of pages unique but repeatedly checked for merging.", prometheus.GaugeValue},
+	{"pages_volatile", "Number of pages changing too fast to be merged.", prometheus.GaugeValue},
+	{"full_scans", "Number of times all mergeable areas have been scanned.", prometheus.CounterValue},
+}
+
+type memDetailCollector struct {
+	swapSize, swapUsed, swapPriority *prometheus.Desc
+	hugepagesDescs                   map[string]*prometheus.Desc
+	ksmDescs                         map[string]*typedDesc
+}
+
+// swapDevice is a line of /proc/swaps.
+type swapDevice struct {
+	filename, swapType string
+	size, used         float64
+	priority           float64
+}
+
+// NewMemDetailCollector returns a new Collector exposing swap device,
+// hugepage pool and KSM stats.
+func NewMemDetailCollector() (Collector, error) {
+	desc := func(name, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, memInfoSubsystem, name),
+			help, labels, nil,
+		)
+	}
+	c := &memDetailCollector{
+		swapSize:       desc("swap_size_bytes", "Size of the swap device in bytes.", "device", "type"),
+		swapUsed:       desc("swap_used_bytes", "Used space of the swap device in bytes.", "device", "type"),
+		swapPriority:   desc("swap_priority", "Priority of the swap device.", "device", "type"),
+		hugepagesDescs: map[string]*prometheus.Desc{},
+		ksmDescs:       map[string]*typedDesc{},
+	}
+	for _, f := range hugepagesFiles {
+		c.hugepagesDescs[f.file] = desc(f.name, f.help, "size")
+	}
+	for _, f := range ksmFiles {
+		c.ksmDescs[f.file] = &typedDesc{desc("ksm_"+f.file, f.help), f.valueType}
+	}
+	return c, nil
+}
+
+func (c *memDetailCollector) Update(ch chan<- prometheus.Metric) error {
+	swaps, err := getSwaps()
+	if err != nil {
+		return fmt.Errorf("couldn't get swaps: %w", err)
+	}
+	for _, swap := range swaps {
+		ch <- prometheus.MustNewConstMetric(c.swapSize, prometheus.GaugeValue, swap.size, swap.filename, swap.swapType)
+		ch <- prometheus.MustNewConstMetric(c.swapUsed, prometheus.GaugeValue, swap.used, swap.filename, swap.swapType)
+		ch <- prometheus.MustNewConstMetric(c.swapPriority, prometheus.GaugeValue, swap.priority, swap.filename, swap.swapType)
+	}
+
+	// Both hugepages and KSM depend on the kernel configuration, so missing
+	// directories are not an error.
+	pools, err := filepath.Glob(sysFilePath("kernel/mm/hugepages/hugepages-*kB"))
+	if err != nil {
+		return err
+	}
+	for _, pool := range pools {
+		sizeKB, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(filepath.Base(pool), "hugepages-"), "kB"), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid hugepages directory %s: %w", pool, err)
+		}
+		size := strconv.FormatUint(sizeKB*1024, 10)
+		for _, f := range hugepagesFiles {
+			value, err := readUintFromFile(filepath.Join(pool, f.file))
+			if err != nil {
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(c.hugepagesDescs[f.file], prometheus.GaugeValue, float64(value), size)
+		}
+	}
+
+	for _, f := range ksmFiles {
+		value, err := readUintFromFile(sysFilePath(filepath.Join("kernel/mm/ksm", f.file)))
+		if err != nil {
+			continue
+		}
+		ch <- c.ksmDescs[f.file].mustNewConstMetric(float64(value))
+	}
+	return nil
+}
+
+func getSwaps() ([]swapDevice, error) {
+	file, err := os.Open(procFilePath("swaps"))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parseSwaps(file)
+}
+
+// parseSwaps parses /proc/swaps, where sizes are in KiB.
+func parseSwaps(r io.Reader) ([]swapDevice, error) {
+	var swaps []swapDevice
+	scanner := bufio.NewScanner(r)
+	scanner.Scan() // skip header
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) != 5 {
+			return nil, fmt.Errorf("invalid line in swaps: %q", scanner.Text())
+		}
+		var values [3]float64
+		for i, part := range parts[2:] {
+			v, err := strconv.ParseFloat(part, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %s in swaps: %w", part, err)
+			}
+			values[i] = v
+		}
+		// Ensure we handle the translation of \040 and \011
+		// as per fstab(5).
+		filename := strings.Replace(parts[0], "\\040", " ", -1)
+		filename = strings.Replace(filename, "\\011", "\t", -1)
+		swaps = append(swaps, swapDevice{
+			filename: filename,
+			swapType: parts[1],
+			size:     values[0] * 1024,
+			used:     values[1] * 1024,
+			priority: values[2],
+		})
+	}
+	return swaps, scanner.Err()
+}
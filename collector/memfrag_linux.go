@@ -0,0 +1,168 @@
+package collector
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// zoneinfoFields are the per-zone page counts of /proc/zoneinfo exposed by
+// the memfrag collector.
+var zoneinfoFields = []struct {
+	field, help string
+}{
+	{"free", "Number of free pages in the zone."},
+	{"min", "Minimum watermark of the zone in pages."},
+	{"low", "Low watermark of the zone in pages, below which kswapd wakes up."},
+	{"high", "High watermark of the zone in pages, at which kswapd goes back to sleep."},
+	{"spanned", "Number of pages spanned by the zone."},
+	{"present", "Number of physical pages present in the zone."},
+	{"managed", "Number of pages managed by the buddy allocator in the zone."},
+}
+
+type memFragCollector struct {
+	buddyinfo *prometheus.Desc
+	zoneDescs map[string]*prometheus.Desc
+}
+
+// zoneKey identifies a memory zone of a NUMA node.
+type zoneKey struct {
+	node, zone string
+}
+
+// NewMemFragCollector returns a new Collector exposing memory fragmentation
+// stats from buddyinfo and zoneinfo.
+func NewMemFragCollector() (Collector, error) {
+	c := &memFragCollector{
+		buddyinfo: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, memInfoSubsystem, "buddyinfo_free_blocks"),
+			"Number of free blocks of 2^order pages.",
+			[]string{"node", "zone", "order"}, nil,
+		),
+		zoneDescs: map[string]*prometheus.Desc{},
+	}
+	for _, f := range zoneinfoFields {
+		c.zoneDescs[f.field] = prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, memInfoSubsystem, "zoneinfo_"+f.field+"_pages"),
+			f.help,
+			[]string{"node", "zone"}, nil,
+		)
+	}
+	return c, nil
+}
+
+func (c *memFragCollector) Update(ch chan<- prometheus.Metric) error {
+	buddyInfo, err := getBuddyInfo()
+	if err != nil {
+		return fmt.Errorf("couldn't get buddyinfo: %w", err)
+	}
+	for zone, blocks := range buddyInfo {
+		for order, count := range blocks {
+			ch <- prometheus.MustNewConstMetric(c.buddyinfo, prometheus.GaugeValue,
+				float64(count), zone.node, zone.zone, strconv.Itoa(order))
+		}
+	}
+
+	zoneInfo, err := getZoneInfo()
+	if err != nil {
+		return fmt.Errorf("couldn't get zoneinfo: %w", err)
+	}
+	for zone, fields := range zoneInfo {
+		for field, value := range fields {
+			ch <- prometheus.MustNewConstMetric(c.zoneDescs[field], prometheus.GaugeValue,
+				float64(value), zone.node, zone.zone)
+		}
+	}
+	return nil
+}
+
+func getBuddyInfo() (map[zoneKey][]uint64, error) {
+	file, err := os.Open(procFilePath("buddyinfo"))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parseBuddyInfo(file)
+}
+
+// parseBuddyInfo parses lines like "Node 0, zone   Normal   6560   1876 ..."
+// into the number of free blocks of every order per zone.
+func parseBuddyInfo(r io.Reader) (map[zoneKey][]uint64, error) {
+	buddyInfo := map[zoneKey][]uint64{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) < 4 || parts[0] != "Node" || parts[2] != "zone" {
+			return nil, fmt.Errorf("invalid line in buddyinfo: %q", scanner.Text())
+		}
+		zone := zoneKey{node: strings.TrimSuffix(parts[1], ","), zone: parts[3]}
+		blocks := make([]uint64, 0, len(parts)-4)
+		for _, part := range parts[4:] {
+			v, err := strconv.ParseUint(part, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %s in buddyinfo: %w", part, err)
+			}
+			blocks = append(blocks, v)
+		}
+		buddyInfo[zone] = blocks
+	}
+	return buddyInfo, scanner.Err()
+}
+
+func getZoneInfo() (map[zoneKey]map[string]uint64, error) {
+	file, err := os.Open(procFilePath("zoneinfo"))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parseZoneInfo(file)
+}
+
+// parseZoneInfo picks the zoneinfoFields of every zone out of /proc/zoneinfo.
+// The free pages are on the "pages free" line, the other fields follow on
+// lines of their own.
+func parseZoneInfo(r io.Reader) (map[zoneKey]map[string]uint64, error) {
+	wanted := map[string]bool{}
+	for _, f := range zoneinfoFields {
+		wanted[f.field] = true
+	}
+
+	zoneInfo := map[zoneKey]map[string]uint64{}
+	var current map[string]uint64
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) == 4 && parts[0] == "Node" && parts[2] == "zone" {
+			zone := zoneKey{node: strings.TrimSuffix(parts[1], ","), zone: parts[3]}
+			current = map[string]uint64{}
+			zoneInfo[zone] = current
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		if len(parts) == 3 && parts[0] == "pages" {
+			parts = parts[1:]
+		}
+		if len(parts) != 2 || !wanted[parts[0]] {
+			continue
+		}
+		// Only the first occurrence belongs to the zone itself.
+		if _, ok := current[parts[0]]; ok {
+			continue
+		}
+		v, err := strconv.ParseUint(parts[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %s in zoneinfo: %w", parts[1], err)
+		}
+		current[parts[0]] = v
+	}
+	return zoneInfo, scanner.Err()
+}
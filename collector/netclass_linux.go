@@ -0,0 +1,192 @@
+package collector
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type netClassCollector struct {
+	deviceExcludeRE  *regexp.Regexp
+	deviceIncludeRE  *regexp.Regexp
+	infoDesc         *prometheus.Desc
+	upDesc           *prometheus.Desc
+	receiveUtilDesc  *prometheus.Desc
+	transmitUtilDesc *prometheus.Desc
+	valueDescs       map[string]*typedDesc
+
+	mtx      sync.Mutex
+	previous map[string]netDevSample
+}
+
+// netClassValues are the numeric files of /sys/class/net/<dev> which are
+// exposed as they are.
+var netClassValues = []struct {
+	file, name, help string
+	valueType        prometheus.ValueType
+}{
+	{"mtu", "mtu_bytes", "Maximum transmission unit of the network device.", prometheus.GaugeValue},
+	{"carrier", "carrier", "Whether the network device has a carrier.", prometheus.GaugeValue},
+	{"carrier_changes", "carrier_changes_total", "Number of carrier changes of the network device.", prometheus.CounterValue},
+	{"carrier_up_count", "carrier_up_changes_total", "Number of times the carrier of the network device went up.", prometheus.CounterValue},
+	{"carrier_down_count", "carrier_down_changes_total", "Number of times the carrier of the network device went down.", prometheus.CounterValue},
+	{"tx_queue_len", "transmit_queue_length", "Transmit queue length of the network device.", prometheus.GaugeValue},
+}
+
+// NewNetClassCollector returns a new Collector exposing network device
+// metadata from /sys/class/net and the link utilisation.
+func NewNetClassCollector() (Collector, error) {
+	excludeRE, includeRE, err := compileNetDevFilters()
+	if err != nil {
+		return nil, err
+	}
+
+	subsystem := "network"
+	valueDescs := map[string]*typedDesc{
+		"speed": {prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "speed_bytes"),
+			"Link speed of the network device in bytes per second.",
+			[]string{"device"}, nil,
+		), prometheus.GaugeValue},
+	}
+	for _, v := range netClassValues {
+		valueDescs[v.file] = &typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, v.name),
+			v.help,
+			[]string{"device"}, nil,
+		), v.valueType}
+	}
+
+	return &netClassCollector{
+		deviceExcludeRE: excludeRE,
+		deviceIncludeRE: includeRE,
+		infoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "info"),
+			"Non-numeric data from /sys/class/net/<device>, value is always 1.",
+			[]string{"device", "address", "operstate", "duplex"}, nil,
+		),
+		upDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "up"),
+			"Whether the operstate of the network device is up.",
+			[]string{"device"}, nil,
+		),
+		receiveUtilDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "receive_utilisation_ratio"),
+			"Receive throughput since the previous update relative to the link speed.",
+			[]string{"device"}, nil,
+		),
+		transmitUtilDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "transmit_utilisation_ratio"),
+			"Transmit throughput since the previous update relative to the link speed.",
+			[]string{"device"}, nil,
+		),
+		valueDescs: valueDescs,
+		previous:   map[string]netDevSample{},
+	}, nil
+}
+
+func (c *netClassCollector) Update(ch chan<- prometheus.Metric) error {
+	devices, err := ioutil.ReadDir(sysFilePath("class/net"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ErrNoData
+		}
+		return fmt.Errorf("couldn't list net devices: %w", err)
+	}
+
+	speeds := map[string]float64{}
+	for _, device := range devices {
+		// Devices are symlinks, while e.g. bonding_masters is a regular file.
+		if device.Mode().IsRegular() {
+			continue
+		}
+		dev := device.Name()
+		if c.deviceExcludeRE != nil && c.deviceExcludeRE.MatchString(dev) {
+			continue
+		}
+		if c.deviceIncludeRE != nil && !c.deviceIncludeRE.MatchString(dev) {
+			continue
+		}
+		if speed, ok := c.updateDevice(ch, dev); ok {
+			speeds[dev] = speed
+		}
+	}
+
+	return c.updateUtilisation(ch, speeds)
+}
+
+// updateDevice sends the metrics of a single device and returns its link
+// speed in bytes per second, if known.
+func (c *netClassCollector) updateDevice(ch chan<- prometheus.Metric, dev string) (float64, bool) {
+	path := sysFilePath(filepath.Join("class/net", dev))
+
+	// Attributes of devices which are down or have no link often can't be
+	// read (EINVAL), so every file is optional.
+	address, _ := readStringFromFile(filepath.Join(path, "address"))
+	operstate, _ := readStringFromFile(filepath.Join(path, "operstate"))
+	duplex, _ := readStringFromFile(filepath.Join(path, "duplex"))
+	ch <- prometheus.MustNewConstMetric(c.infoDesc, prometheus.GaugeValue, 1, dev, address, operstate, duplex)
+
+	var up float64
+	if operstate == "up" {
+		up = 1
+	}
+	ch <- prometheus.MustNewConstMetric(c.upDesc, prometheus.GaugeValue, up, dev)
+
+	for _, v := range netClassValues {
+		value, err := readUintFromFile(filepath.Join(path, v.file))
+		if err != nil {
+			continue
+		}
+		ch <- c.valueDescs[v.file].mustNewConstMetric(float64(value), dev)
+	}
+
+	// speed is in Mbit/s and -1 when unknown, which readUintFromFile rejects.
+	speed, err := readUintFromFile(filepath.Join(path, "speed"))
+	if err != nil || speed == 0 {
+		return 0, false
+	}
+	bytesPerSecond := float64(speed) * 1000 * 1000 / 8
+	ch <- c.valueDescs["speed"].mustNewConstMetric(bytesPerSecond, dev)
+	return bytesPerSecond, true
+}
+
+// updateUtilisation combines the net/dev byte counters with the link speeds
+// into receive and transmit utilisation ratios.
+func (c *netClassCollector) updateUtilisation(ch chan<- prometheus.Metric, speeds map[string]float64) error {
+	netDev, _, err := getNetDevStats(c.deviceExcludeRE, c.deviceIncludeRE)
+	if err != nil {
+		return fmt.Errorf("couldn't get netstats: %s", err)
+	}
+	now := time.Now()
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	current := make(map[string]netDevSample, len(netDev))
+	for dev, stats := range netDev {
+		sample := newNetDevSample(stats, now)
+		current[dev] = sample
+
+		speed, ok := speeds[dev]
+		if !ok {
+			continue
+		}
+		prev, ok := c.previous[dev]
+		if !ok {
+			continue
+		}
+		if receive, transmit, ok := sample.rates(prev); ok {
+			ch <- prometheus.MustNewConstMetric(c.receiveUtilDesc, prometheus.GaugeValue, receive/speed, dev)
+			ch <- prometheus.MustNewConstMetric(c.transmitUtilDesc, prometheus.GaugeValue, transmit/speed, dev)
+		}
+	}
+	c.previous = current
+	return nil
+}
@@ -0,0 +1,33 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNetClassCollector(t *testing.T) {
+	withFixtures(t)
+	c, err := NewNetClassCollector()
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics := collectMetrics(t, c)
+
+	for name, want := range map[string]float64{
+		`propush_network_up{device="eth0"}`:                                                            1,
+		`propush_network_mtu_bytes{device="eth0"}`:                                                     1500,
+		`propush_network_carrier{device="eth0"}`:                                                       1,
+		`propush_network_speed_bytes{device="eth0"}`:                                                   125000000,
+		`propush_network_info{address="52:54:00:12:34:56",device="eth0",duplex="full",operstate="up"}`: 1,
+	} {
+		if got, ok := metrics[name]; !ok || got != want {
+			t.Errorf("%s = %v (present %v), want %v", name, got, ok, want)
+		}
+	}
+	// bonding_masters is a regular file next to the device symlinks.
+	for name := range metrics {
+		if strings.Contains(name, "bonding_masters") {
+			t.Errorf("unexpected metric %s", name)
+		}
+	}
+}
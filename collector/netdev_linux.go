@@ -2,58 +2,198 @@ package collector
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+var (
+	// NetDevDeviceExclude is a regexp of net devices to exclude from the
+	// netio collector. It is mutually exclusive to NetDevDeviceInclude.
+	NetDevDeviceExclude string
+	// NetDevDeviceInclude is a regexp of net devices to include in the
+	// netio collector. It is mutually exclusive to NetDevDeviceExclude.
+	NetDevDeviceInclude string
+	// NetDevNetlink selects reading the net device stats via rtnetlink
+	// instead of parsing /proc/net/dev. /proc/net/dev is still used as a
+	// fallback if the netlink request fails.
+	NetDevNetlink bool
+)
+
+// netDevFields are the counters of a net device, named after the columns of
+// /proc/net/dev, in their order.
+var netDevFields = [...]string{
+	"receive_bytes", "receive_packets", "receive_errs", "receive_drop",
+	"receive_fifo", "receive_frame", "receive_compressed", "receive_multicast",
+	"transmit_bytes", "transmit_packets", "transmit_errs", "transmit_drop",
+	"transmit_fifo", "transmit_colls", "transmit_carrier", "transmit_compressed",
+}
+
+// Indexes of netDevFields.
+const (
+	netDevReceiveBytes  = 0
+	netDevTransmitBytes = 8
+)
+
+// netDevStats is the counters of a net device, indexed like netDevFields.
+// Being of fixed size, it takes a single allocation per device, unlike a map
+// keyed by field, which matters on hosts with thousands of devices.
+type netDevStats struct {
+	// ifIndex tells apart devices which were recreated under the same name,
+	// whose counters started over. It is 0 if unknown.
+	ifIndex uint64
+	values  [len(netDevFields)]uint64
+}
+
 type netDevCollector struct {
-	subsystem   string
-	metricDescs map[string]*prometheus.Desc
+	subsystem        string
+	deviceExcludeRE  *regexp.Regexp
+	deviceIncludeRE  *regexp.Regexp
+	metricDescs      [len(netDevFields)]*prometheus.Desc
+	receiveRateDesc  *prometheus.Desc
+	transmitRateDesc *prometheus.Desc
+	netlinkErrorDesc *prometheus.Desc
+
+	mtx      sync.Mutex
+	previous map[string]netDevSample
+}
+
+// netDevSample is the byte counters of a device at a point in time, kept
+// between updates to compute throughput rates.
+type netDevSample struct {
+	ifIndex                     uint64
+	receiveBytes, transmitBytes uint64
+	time                        time.Time
+}
+
+func newNetDevSample(stats *netDevStats, now time.Time) netDevSample {
+	return netDevSample{
+		ifIndex:       stats.ifIndex,
+		receiveBytes:  stats.values[netDevReceiveBytes],
+		transmitBytes: stats.values[netDevTransmitBytes],
+		time:          now,
+	}
+}
+
+// rates returns the receive and transmit throughput from prev to s, if the
+// counters of the device didn't start over in between.
+func (s netDevSample) rates(prev netDevSample) (receive, transmit float64, ok bool) {
+	if s.ifIndex != prev.ifIndex {
+		return 0, 0, false
+	}
+	elapsed := s.time.Sub(prev.time).Seconds()
+	receive, receiveOK := counterRate(prev.receiveBytes, s.receiveBytes, elapsed)
+	transmit, transmitOK := counterRate(prev.transmitBytes, s.transmitBytes, elapsed)
+	return receive, transmit, receiveOK && transmitOK
 }
 
 // NewNetDevCollector returns a new Collector exposing network device stats.
 func NewNetDevCollector() (Collector, error) {
-	return &netDevCollector{
-		subsystem:   "network",
-		metricDescs: map[string]*prometheus.Desc{},
-	}, nil
+	excludeRE, includeRE, err := compileNetDevFilters()
+	if err != nil {
+		return nil, err
+	}
+
+	subsystem := "network"
+	c := &netDevCollector{
+		subsystem:       subsystem,
+		deviceExcludeRE: excludeRE,
+		deviceIncludeRE: includeRE,
+		receiveRateDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "receive_bytes_per_second"),
+			"Network device receive throughput since the previous update.",
+			[]string{"device"}, nil,
+		),
+		transmitRateDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "transmit_bytes_per_second"),
+			"Network device transmit throughput since the previous update.",
+			[]string{"device"}, nil,
+		),
+		netlinkErrorDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "netlink_error"),
+			"1 if the stats couldn't be read via rtnetlink and were read from /proc/net/dev instead, 0 otherwise.",
+			nil, nil,
+		),
+		previous: map[string]netDevSample{},
+	}
+	for i, field := range netDevFields {
+		c.metricDescs[i] = prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, field+"_total"),
+			fmt.Sprintf("Network device statistic %s.", field),
+			[]string{"device"},
+			nil,
+		)
+	}
+	return c, nil
+}
+
+// compileNetDevFilters compiles NetDevDeviceExclude and NetDevDeviceInclude,
+// returning nil for the ones which are not set.
+func compileNetDevFilters() (exclude, include *regexp.Regexp, err error) {
+	if NetDevDeviceExclude != "" && NetDevDeviceInclude != "" {
+		return nil, nil, errors.New("device-exclude & device-include are mutually exclusive")
+	}
+	if NetDevDeviceExclude != "" {
+		if exclude, err = regexp.Compile(NetDevDeviceExclude); err != nil {
+			return nil, nil, fmt.Errorf("invalid device-exclude pattern: %w", err)
+		}
+	}
+	if NetDevDeviceInclude != "" {
+		if include, err = regexp.Compile(NetDevDeviceInclude); err != nil {
+			return nil, nil, fmt.Errorf("invalid device-include pattern: %w", err)
+		}
+	}
+	return exclude, include, nil
 }
 
 func (c *netDevCollector) Update(ch chan<- prometheus.Metric) error {
-	netDev, err := getNetDevStats(nil, nil)
+	netDev, netlinkErr, err := getNetDevStats(c.deviceExcludeRE, c.deviceIncludeRE)
 	if err != nil {
 		return fmt.Errorf("couldn't get netstats: %s", err)
 	}
+	if NetDevNetlink {
+		var netlinkError float64
+		if netlinkErr != nil {
+			netlinkError = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.netlinkErrorDesc, prometheus.GaugeValue, netlinkError)
+	}
+	now := time.Now()
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
 	var res float64
-	for _, devStats := range netDev {
-		for key, value := range devStats {
-			if key != "receive_bytes" && key != "transmit_bytes" {
-				continue
-			}
-			desc, ok := c.metricDescs[key]
-			if !ok {
-				desc = prometheus.NewDesc(
-					prometheus.BuildFQName(namespace, c.subsystem, key+"_total"),
-					fmt.Sprintf("Network device statistic %s.", key),
-					[]string{"device"},
-					nil,
-				)
-				c.metricDescs[key] = desc
-			}
-			v, err := strconv.ParseFloat(value, 64)
-			if err != nil {
-				return fmt.Errorf("invalid value %s in netstats: %s", value, err)
+	current := make(map[string]netDevSample, len(netDev))
+	for dev, stats := range netDev {
+		sample := newNetDevSample(stats, now)
+		current[dev] = sample
+
+		if prev, ok := c.previous[dev]; ok {
+			if receive, transmit, ok := sample.rates(prev); ok {
+				ch <- prometheus.MustNewConstMetric(c.receiveRateDesc, prometheus.GaugeValue, receive, dev)
+				ch <- prometheus.MustNewConstMetric(c.transmitRateDesc, prometheus.GaugeValue, transmit, dev)
 			}
-			res += v
-			//ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, v, dev)
 		}
+
+		for i, value := range stats.values {
+			ch <- prometheus.MustNewConstMetric(c.metricDescs[i], prometheus.CounterValue, float64(value), dev)
+		}
+		res += float64(stats.values[netDevReceiveBytes]) + float64(stats.values[netDevTransmitBytes])
 	}
+	// Devices which disappeared are dropped here, so a device coming back
+	// starts over without a bogus rate.
+	c.previous = current
+
 	// Bytes => MB
 	res = res / 1024 / 1024
 	ch <- prometheus.MustNewConstMetric(
@@ -67,22 +207,52 @@ func (c *netDevCollector) Update(ch chan<- prometheus.Metric) error {
 	return nil
 }
 
-var (
-	procNetDevInterfaceRE = regexp.MustCompile(`^(.+): *(.+)$`)
-	procNetDevFieldSep    = regexp.MustCompile(` +`)
-)
+// counterRate returns the per-second increase of a counter from prev to cur
+// over elapsed seconds. The counters are 64-bit, so a counter which went
+// backwards was reset and no rate is returned.
+func counterRate(prev, cur uint64, elapsed float64) (float64, bool) {
+	if elapsed <= 0 || cur < prev {
+		return 0, false
+	}
+	return float64(cur-prev) / elapsed, true
+}
 
-func getNetDevStats(ignore *regexp.Regexp, accept *regexp.Regexp) (map[string]map[string]string, error) {
+var procNetDevInterfaceRE = regexp.MustCompile(`^(.+): *(.+)$`)
+
+// getNetDevStats returns the stats of every net device, keyed by device. If
+// NetDevNetlink is set but the netlink request fails, the stats are read from
+// /proc/net/dev and the netlink error is returned as netlinkErr.
+func getNetDevStats(ignore *regexp.Regexp, accept *regexp.Regexp) (netDev map[string]*netDevStats, netlinkErr, err error) {
+	if NetDevNetlink {
+		if netDev, netlinkErr = getNetDevNetlinkStats(ignore, accept); netlinkErr == nil {
+			return netDev, nil, nil
+		}
+	}
+	netDev, err = getNetDevProcStats(ignore, accept)
+	return netDev, netlinkErr, err
+}
+
+func getNetDevProcStats(ignore *regexp.Regexp, accept *regexp.Regexp) (map[string]*netDevStats, error) {
 	file, err := os.Open(procFilePath("net/dev"))
 	if err != nil {
 		return nil, err
 	}
 	defer file.Close()
 
-	return parseNetDevStats(file, ignore, accept)
+	netDev, err := parseNetDevStats(file, ignore, accept)
+	if err != nil {
+		return nil, err
+	}
+	// /proc/net/dev has no ifindex, unlike netlink.
+	for dev, stats := range netDev {
+		stats.ifIndex, _ = readUintFromFile(sysFilePath(filepath.Join("class/net", dev, "ifindex")))
+	}
+	return netDev, nil
 }
 
-func parseNetDevStats(r io.Reader, ignore *regexp.Regexp, accept *regexp.Regexp) (map[string]map[string]string, error) {
+// parseNetDevStats parses /proc/net/dev. Its columns are looked up by the
+// names in the header, and columns unknown to netDevFields are skipped.
+func parseNetDevStats(r io.Reader, ignore *regexp.Regexp, accept *regexp.Regexp) (map[string]*netDevStats, error) {
 	scanner := bufio.NewScanner(r)
 	scanner.Scan() // skip first header
 	scanner.Scan()
@@ -92,11 +262,25 @@ func parseNetDevStats(r io.Reader, ignore *regexp.Regexp, accept *regexp.Regexp)
 			scanner.Text())
 	}
 
-	receiveHeader := strings.Fields(parts[1])
-	transmitHeader := strings.Fields(parts[2])
-	headerLength := len(receiveHeader) + len(transmitHeader)
+	// columns maps every column to its index in netDevFields, or -1.
+	var columns []int
+	for _, header := range []struct{ prefix, names string }{
+		{"receive_", parts[1]},
+		{"transmit_", parts[2]},
+	} {
+		for _, name := range strings.Fields(header.names) {
+			index := -1
+			for i, field := range netDevFields {
+				if field == header.prefix+name {
+					index = i
+					break
+				}
+			}
+			columns = append(columns, index)
+		}
+	}
 
-	netDev := map[string]map[string]string{}
+	netDev := map[string]*netDevStats{}
 	for scanner.Scan() {
 		line := strings.TrimLeft(scanner.Text(), " ")
 		parts := procNetDevInterfaceRE.FindStringSubmatch(line)
@@ -112,19 +296,23 @@ func parseNetDevStats(r io.Reader, ignore *regexp.Regexp, accept *regexp.Regexp)
 			continue
 		}
 
-		values := procNetDevFieldSep.Split(strings.TrimLeft(parts[2], " "), -1)
-		if len(values) != headerLength {
+		values := strings.Fields(parts[2])
+		if len(values) != len(columns) {
 			return nil, fmt.Errorf("couldn't get values, invalid line in net/dev: %q", parts[2])
 		}
 
-		netDev[dev] = map[string]string{}
-		for i := 0; i < len(receiveHeader); i++ {
-			netDev[dev]["receive_"+receiveHeader[i]] = values[i]
-		}
-
-		for i := 0; i < len(transmitHeader); i++ {
-			netDev[dev]["transmit_"+transmitHeader[i]] = values[i+len(receiveHeader)]
+		stats := &netDevStats{}
+		for i, value := range values {
+			if columns[i] < 0 {
+				continue
+			}
+			v, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %s in net/dev: %s", value, err)
+			}
+			stats.values[columns[i]] = v
 		}
+		netDev[dev] = stats
 	}
 	return netDev, scanner.Err()
 }
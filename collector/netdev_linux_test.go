@@ -0,0 +1,83 @@
+package collector
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCounterRate(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		prev, cur uint64
+		elapsed   float64
+		want      float64
+		wantOK    bool
+	}{
+		{"increase", 1000, 4000, 30, 100, true},
+		{"unchanged", 1000, 1000, 30, 0, true},
+		{"reset below 4GiB", 1 << 30, 10 << 10, 30, 0, false},
+		{"reset above 4GiB", 1 << 40, 10, 30, 0, false},
+		{"no time elapsed", 1000, 4000, 0, 0, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := counterRate(tc.prev, tc.cur, tc.elapsed)
+			if got != tc.want || ok != tc.wantOK {
+				t.Errorf("counterRate(%d, %d, %v) = %v, %v; want %v, %v", tc.prev, tc.cur, tc.elapsed, got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestNetDevSampleRatesRecreatedDevice(t *testing.T) {
+	now := time.Now()
+	prev := netDevSample{ifIndex: 7, receiveBytes: 1000, transmitBytes: 1000, time: now}
+	cur := netDevSample{ifIndex: 8, receiveBytes: 4000, transmitBytes: 4000, time: now.Add(30 * time.Second)}
+	if _, _, ok := cur.rates(prev); ok {
+		t.Error("got a rate across a recreated device")
+	}
+	cur.ifIndex = prev.ifIndex
+	receive, transmit, ok := cur.rates(prev)
+	if !ok || receive != 100 || transmit != 100 {
+		t.Errorf("rates() = %v, %v, %v; want 100, 100, true", receive, transmit, ok)
+	}
+}
+
+func TestParseNetDevStats(t *testing.T) {
+	const netDev = `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo: 50388963    5007    0    0    0     0          0         0 50388963    5007    0    0    0     0       0          0
+  eth0: 1914579     171    1    2    0     0          0         3    26925     285    0    4    0     0       5          0
+ veth1a2b: 10 1 0 0 0 0 0 0 20 2 0 0 0 0 0 0
+`
+	netDevStats, err := parseNetDevStats(strings.NewReader(netDev), regexp.MustCompile("^veth"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(netDevStats) != 2 {
+		t.Fatalf("got %d devices, want 2", len(netDevStats))
+	}
+	eth0 := netDevStats["eth0"].values
+	for field, want := range map[string]uint64{
+		"receive_bytes":     1914579,
+		"receive_errs":      1,
+		"receive_drop":      2,
+		"receive_multicast": 3,
+		"transmit_bytes":    26925,
+		"transmit_drop":     4,
+		"transmit_carrier":  5,
+	} {
+		for i, name := range netDevFields {
+			if name == field && eth0[i] != want {
+				t.Errorf("%s = %d, want %d", field, eth0[i], want)
+			}
+		}
+	}
+	if got := eth0[netDevReceiveBytes]; got != 1914579 {
+		t.Errorf("receive bytes = %d, want 1914579", got)
+	}
+	if got := eth0[netDevTransmitBytes]; got != 26925 {
+		t.Errorf("transmit bytes = %d, want 26925", got)
+	}
+}
@@ -0,0 +1,118 @@
+package collector
+
+import (
+	"fmt"
+	"regexp"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// rtnlLinkStats64 mirrors the leading fields of struct rtnl_link_stats64
+// from include/uapi/linux/if_link.h, which newer kernels extend at the end.
+type rtnlLinkStats64 struct {
+	RxPackets, TxPackets       uint64
+	RxBytes, TxBytes           uint64
+	RxErrors, TxErrors         uint64
+	RxDropped, TxDropped       uint64
+	Multicast, Collisions      uint64
+	RxLengthErrors             uint64
+	RxOverErrors               uint64
+	RxCrcErrors                uint64
+	RxFrameErrors              uint64
+	RxFifoErrors               uint64
+	RxMissedErrors             uint64
+	TxAbortedErrors            uint64
+	TxCarrierErrors            uint64
+	TxFifoErrors               uint64
+	TxHeartbeatErrors          uint64
+	TxWindowErrors             uint64
+	RxCompressed, TxCompressed uint64
+}
+
+const sizeofRtnlLinkStats64 = int(unsafe.Sizeof(rtnlLinkStats64{}))
+
+// getNetDevNetlinkStats dumps the links via rtnetlink and returns their
+// IFLA_STATS64 counters, folded together the way the kernel does for
+// /proc/net/dev.
+func getNetDevNetlinkStats(ignore *regexp.Regexp, accept *regexp.Regexp) (map[string]*netDevStats, error) {
+	tab, err := syscall.NetlinkRIB(syscall.RTM_GETLINK, syscall.AF_UNSPEC)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't dump links: %w", err)
+	}
+	msgs, err := syscall.ParseNetlinkMessage(tab)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse link dump: %w", err)
+	}
+
+	netDev := map[string]*netDevStats{}
+	for _, m := range msgs {
+		if m.Header.Type == syscall.NLMSG_DONE {
+			break
+		}
+		if m.Header.Type != syscall.RTM_NEWLINK {
+			continue
+		}
+		if len(m.Data) < syscall.SizeofIfInfomsg {
+			return nil, fmt.Errorf("short link message: %d bytes", len(m.Data))
+		}
+		ifInfo := (*syscall.IfInfomsg)(unsafe.Pointer(&m.Data[0]))
+		attrs, err := syscall.ParseNetlinkRouteAttr(&m)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse link attributes: %w", err)
+		}
+
+		var dev string
+		var stats *rtnlLinkStats64
+		for _, attr := range attrs {
+			switch attr.Attr.Type {
+			case unix.IFLA_IFNAME:
+				// The name is NUL terminated.
+				if len(attr.Value) > 0 {
+					dev = string(attr.Value[:len(attr.Value)-1])
+				}
+			case unix.IFLA_STATS64:
+				if len(attr.Value) < sizeofRtnlLinkStats64 {
+					return nil, fmt.Errorf("short IFLA_STATS64 attribute: %d bytes", len(attr.Value))
+				}
+				// Copy into an aligned struct in native byte order.
+				stats = new(rtnlLinkStats64)
+				copy((*[sizeofRtnlLinkStats64]byte)(unsafe.Pointer(stats))[:], attr.Value)
+			}
+		}
+		if dev == "" || stats == nil {
+			continue
+		}
+		if ignore != nil && ignore.MatchString(dev) {
+			continue
+		}
+		if accept != nil && !accept.MatchString(dev) {
+			continue
+		}
+
+		netDev[dev] = &netDevStats{
+			ifIndex: uint64(ifInfo.Index),
+			values: [len(netDevFields)]uint64{
+				stats.RxBytes,
+				stats.RxPackets,
+				stats.RxErrors,
+				stats.RxDropped + stats.RxMissedErrors,
+				stats.RxFifoErrors,
+				stats.RxLengthErrors + stats.RxOverErrors + stats.RxCrcErrors + stats.RxFrameErrors,
+				stats.RxCompressed,
+				stats.Multicast,
+
+				stats.TxBytes,
+				stats.TxPackets,
+				stats.TxErrors,
+				stats.TxDropped,
+				stats.TxFifoErrors,
+				stats.Collisions,
+				stats.TxCarrierErrors + stats.TxAbortedErrors + stats.TxWindowErrors + stats.TxHeartbeatErrors,
+				stats.TxCompressed,
+			},
+		}
+	}
+	return netDev, nil
+}
@@ -0,0 +1,185 @@
+package collector
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	netStatsSubsystem = "netstat"
+)
+
+// NetStatFields is a regexp of the fields to return for the netstat
+// collector, matched against "<Protocol>_<Field>", e.g. "Tcp_RetransSegs".
+var NetStatFields = "^(.*_(InErrors|InErrs)|Ip_Forwarding|Ip(6|Ext)_(InOctets|OutOctets)|Icmp6?_(InMsgs|OutMsgs)|TcpExt_(Listen.*|Syncookies.*|TCPSynRetrans|TCPTimeouts|TCPOFOQueue|TCPRcvQDrop)|Tcp_(ActiveOpens|InSegs|OutSegs|OutRsts|PassiveOpens|RetransSegs|CurrEstab)|Udp6?_(InDatagrams|OutDatagrams|NoPorts|RcvbufErrors|SndbufErrors))$"
+
+// netStatGauges are the fields which hold a current value or a setting
+// rather than a counter.
+var netStatGauges = map[string]bool{
+	"Ip_Forwarding":    true,
+	"Ip_DefaultTTL":    true,
+	"Tcp_RtoAlgorithm": true,
+	"Tcp_RtoMin":       true,
+	"Tcp_RtoMax":       true,
+	"Tcp_MaxConn":      true,
+	"Tcp_CurrEstab":    true,
+}
+
+type netStatCollector struct {
+	fieldPattern *regexp.Regexp
+}
+
+// NewNetStatCollector returns a new Collector exposing network protocol
+// stats from /proc/net/{snmp,snmp6,netstat}.
+func NewNetStatCollector() (Collector, error) {
+	pattern, err := regexp.Compile(NetStatFields)
+	if err != nil {
+		return nil, fmt.Errorf("invalid netstat fields pattern: %w", err)
+	}
+	return &netStatCollector{
+		fieldPattern: pattern,
+	}, nil
+}
+
+func (c *netStatCollector) Update(ch chan<- prometheus.Metric) error {
+	netStats, err := getNetStats(procFilePath("net/netstat"))
+	if err != nil {
+		return fmt.Errorf("couldn't get netstats: %w", err)
+	}
+	snmpStats, err := getNetStats(procFilePath("net/snmp"))
+	if err != nil {
+		return fmt.Errorf("couldn't get SNMP stats: %w", err)
+	}
+	snmp6Stats, err := getSNMP6Stats(procFilePath("net/snmp6"))
+	if err != nil {
+		return fmt.Errorf("couldn't get SNMP6 stats: %w", err)
+	}
+	// Merge the results of snmpStats and snmp6Stats into netStats (collisions are possible, but
+	// we know that the keys are always unique for the given use case).
+	for k, v := range snmpStats {
+		netStats[k] = v
+	}
+	for k, v := range snmp6Stats {
+		netStats[k] = v
+	}
+	for protocol, protocolStats := range netStats {
+		for name, value := range protocolStats {
+			key := protocol + "_" + name
+			if !c.fieldPattern.MatchString(key) {
+				continue
+			}
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("invalid value %s in netstats: %w", value, err)
+			}
+			valueType := prometheus.CounterValue
+			if netStatGauges[key] {
+				valueType = prometheus.GaugeValue
+			}
+			ch <- prometheus.MustNewConstMetric(
+				prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, netStatsSubsystem, key),
+					fmt.Sprintf("Statistic %s%s.", protocol, name),
+					nil, nil,
+				),
+				valueType, v,
+			)
+		}
+	}
+	return nil
+}
+
+func getNetStats(fileName string) (map[string]map[string]string, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parseNetStats(file, fileName)
+}
+
+// parseNetStats parses files like /proc/net/netstat and /proc/net/snmp, in
+// which every protocol has a line of field names followed by a line of
+// values, both starting with "<Protocol>:".
+func parseNetStats(r io.Reader, fileName string) (map[string]map[string]string, error) {
+	var (
+		netStats = map[string]map[string]string{}
+		scanner  = bufio.NewScanner(r)
+	)
+
+	for scanner.Scan() {
+		nameParts := strings.Fields(scanner.Text())
+		if len(nameParts) == 0 || !strings.HasSuffix(nameParts[0], ":") {
+			return nil, fmt.Errorf("invalid line in %s: %q", fileName, scanner.Text())
+		}
+		protocol := strings.TrimSuffix(nameParts[0], ":")
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("missing values of %s in %s", protocol, fileName)
+		}
+		valueParts := strings.Fields(scanner.Text())
+		if len(valueParts) == 0 || valueParts[0] != nameParts[0] {
+			return nil, fmt.Errorf("invalid values of %s in %s: %q", protocol, fileName, scanner.Text())
+		}
+		if len(nameParts) != len(valueParts) {
+			return nil, fmt.Errorf("field count mismatch in %s: %s",
+				fileName, protocol)
+		}
+		netStats[protocol] = map[string]string{}
+		for i := 1; i < len(nameParts); i++ {
+			netStats[protocol][nameParts[i]] = valueParts[i]
+		}
+	}
+
+	return netStats, scanner.Err()
+}
+
+func getSNMP6Stats(fileName string) (map[string]map[string]string, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		// On systems with IPv6 disabled, this file won't exist.
+		// Do nothing.
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	return parseSNMP6Stats(file)
+}
+
+func parseSNMP6Stats(r io.Reader) (map[string]map[string]string, error) {
+	var (
+		netStats = map[string]map[string]string{}
+		scanner  = bufio.NewScanner(r)
+	)
+
+	for scanner.Scan() {
+		stat := strings.Fields(scanner.Text())
+		if len(stat) < 2 {
+			continue
+		}
+		// Expect to have "6" in metric name, skip line otherwise
+		if sixIndex := strings.Index(stat[0], "6"); sixIndex != -1 {
+			protocol := stat[0][:sixIndex+1]
+			name := stat[0][sixIndex+1:]
+			if _, present := netStats[protocol]; !present {
+				netStats[protocol] = map[string]string{}
+			}
+			netStats[protocol][name] = stat[1]
+		}
+	}
+
+	return netStats, scanner.Err()
+}
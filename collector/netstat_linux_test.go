@@ -0,0 +1,87 @@
+package collector
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseNetStats(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		file    string
+		want    map[string]map[string]string
+		wantErr bool
+	}{
+		{
+			name: "snmp",
+			file: "Ip: Forwarding DefaultTTL InReceives\n" +
+				"Ip: 2 64 6334\n" +
+				"Tcp: RtoAlgorithm CurrEstab\n" +
+				"Tcp: 1 3\n",
+			want: map[string]map[string]string{
+				"Ip":  {"Forwarding": "2", "DefaultTTL": "64", "InReceives": "6334"},
+				"Tcp": {"RtoAlgorithm": "1", "CurrEstab": "3"},
+			},
+		},
+		{
+			name: "trailing spaces",
+			file: "TcpExt: SyncookiesSent ListenDrops \nTcpExt: 0 7 \n",
+			want: map[string]map[string]string{
+				"TcpExt": {"SyncookiesSent": "0", "ListenDrops": "7"},
+			},
+		},
+		{
+			name:    "empty line",
+			file:    "Ip: Forwarding\nIp: 2\n\n",
+			wantErr: true,
+		},
+		{
+			name:    "odd number of lines",
+			file:    "Ip: Forwarding\nIp: 2\nTcp: CurrEstab\n",
+			wantErr: true,
+		},
+		{
+			name:    "values of another protocol",
+			file:    "Ip: Forwarding\nTcp: 2\n",
+			wantErr: true,
+		},
+		{
+			name:    "field count mismatch",
+			file:    "Ip: Forwarding DefaultTTL\nIp: 2\n",
+			wantErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseNetStats(strings.NewReader(tc.file), tc.name)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseSNMP6Stats(t *testing.T) {
+	file := "Ip6InReceives                   \t1024\nIcmp6InMsgs 3\nUdpLite6InErrors 0\n"
+	got, err := parseSNMP6Stats(strings.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]map[string]string{
+		"Ip6":      {"InReceives": "1024"},
+		"Icmp6":    {"InMsgs": "3"},
+		"UdpLite6": {"InErrors": "0"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
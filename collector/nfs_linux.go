@@ -0,0 +1,212 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/procfs"
+	"github.com/prometheus/procfs/nfs"
+)
+
+const (
+	nfsSubsystem  = "nfs"
+	nfsdSubsystem = "nfsd"
+)
+
+var nfsMountLabelNames = []string{"export", "mountpoint", "protocol"}
+
+type nfsCollector struct {
+	procFS procfs.FS
+	nfsFS  nfs.FS
+
+	rpcs, retransmissions, authRefreshes *prometheus.Desc
+	requests                             *prometheus.Desc
+
+	serverRPCs, serverRPCErrors       *prometheus.Desc
+	serverRequests                    *prometheus.Desc
+	serverReadBytes, serverWriteBytes *prometheus.Desc
+
+	mountAge, mountReadBytes, mountWriteBytes    *prometheus.Desc
+	opRequests, opTransmissions, opMajorTimeouts *prometheus.Desc
+	opQueueTime, opResponseTime, opRequestTime   *prometheus.Desc
+	opErrors                                     *prometheus.Desc
+}
+
+// NewNFSCollector returns a new Collector exposing NFS client, server and
+// per-mount stats.
+func NewNFSCollector() (Collector, error) {
+	procFS, err := procfs.NewFS(procPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open procfs: %w", err)
+	}
+	nfsFS, err := nfs.NewFS(procPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open procfs: %w", err)
+	}
+
+	desc := func(subsystem, name, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, name),
+			help, labels, nil,
+		)
+	}
+	mountDesc := func(name, help string) *prometheus.Desc {
+		return desc(nfsSubsystem, "mount_"+name, help, nfsMountLabelNames...)
+	}
+	opDesc := func(name, help string) *prometheus.Desc {
+		return desc(nfsSubsystem, "mount_operation_"+name, help, append(nfsMountLabelNames, "operation")...)
+	}
+	return &nfsCollector{
+		procFS: procFS,
+		nfsFS:  nfsFS,
+
+		rpcs:            desc(nfsSubsystem, "rpcs_total", "Number of RPCs performed by the NFS client."),
+		retransmissions: desc(nfsSubsystem, "rpc_retransmissions_total", "Number of RPCs retransmitted by the NFS client."),
+		authRefreshes:   desc(nfsSubsystem, "rpc_authentication_refreshes_total", "Number of RPC authentication refreshes performed by the NFS client."),
+		requests:        desc(nfsSubsystem, "requests_total", "Number of NFS procedures invoked by the client.", "proto", "method"),
+
+		serverRPCs:       desc(nfsdSubsystem, "rpcs_total", "Number of RPCs handled by the NFS server."),
+		serverRPCErrors:  desc(nfsdSubsystem, "rpc_errors_total", "Number of bad RPCs received by the NFS server.", "error"),
+		serverRequests:   desc(nfsdSubsystem, "requests_total", "Number of NFS procedures handled by the server.", "proto", "method"),
+		serverReadBytes:  desc(nfsdSubsystem, "disk_bytes_read_total", "Number of bytes read from disk by the NFS server."),
+		serverWriteBytes: desc(nfsdSubsystem, "disk_bytes_written_total", "Number of bytes written to disk by the NFS server."),
+
+		mountAge:        mountDesc("age_seconds", "Time since the NFS export was mounted."),
+		mountReadBytes:  mountDesc("read_bytes_total", "Number of bytes read by applications from the NFS mount."),
+		mountWriteBytes: mountDesc("write_bytes_total", "Number of bytes written by applications to the NFS mount."),
+		opRequests:      opDesc("requests_total", "Number of requests of the operation."),
+		opTransmissions: opDesc("transmissions_total", "Number of transmissions of the operation, including retransmissions."),
+		opMajorTimeouts: opDesc("major_timeouts_total", "Number of major timeouts of the operation."),
+		opQueueTime:     opDesc("queue_time_seconds_total", "Time requests of the operation spent queued before transmission."),
+		opResponseTime:  opDesc("response_time_seconds_total", "Time spent waiting for responses to the operation."),
+		opRequestTime:   opDesc("request_time_seconds_total", "Total time requests of the operation took from queueing to completion."),
+		opErrors:        opDesc("errors_total", "Number of requests of the operation which completed with an error."),
+	}, nil
+}
+
+func (c *nfsCollector) Update(ch chan<- prometheus.Metric) error {
+	var found bool
+	for _, update := range []func(chan<- prometheus.Metric) error{
+		c.updateClient,
+		c.updateServer,
+		c.updateMounts,
+	} {
+		err := update(ch)
+		if err == ErrNoData {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		found = true
+	}
+	if !found {
+		return ErrNoData
+	}
+	return nil
+}
+
+func (c *nfsCollector) updateClient(ch chan<- prometheus.Metric) error {
+	stats, err := c.nfsFS.ClientRPCStats()
+	if err != nil {
+		// The nfs module isn't loaded.
+		if errors.Is(err, os.ErrNotExist) {
+			return ErrNoData
+		}
+		return fmt.Errorf("failed to retrieve nfs stats: %w", err)
+	}
+	ch <- prometheus.MustNewConstMetric(c.rpcs, prometheus.CounterValue, float64(stats.ClientRPC.RPCCount))
+	ch <- prometheus.MustNewConstMetric(c.retransmissions, prometheus.CounterValue, float64(stats.ClientRPC.Retransmissions))
+	ch <- prometheus.MustNewConstMetric(c.authRefreshes, prometheus.CounterValue, float64(stats.ClientRPC.AuthRefreshes))
+	sendNFSProcedures(ch, c.requests, "2", stats.V2Stats)
+	sendNFSProcedures(ch, c.requests, "3", stats.V3Stats)
+	sendNFSProcedures(ch, c.requests, "4", stats.ClientV4Stats)
+	return nil
+}
+
+func (c *nfsCollector) updateServer(ch chan<- prometheus.Metric) error {
+	stats, err := c.nfsFS.ServerRPCStats()
+	if err != nil {
+		// The nfsd module isn't loaded.
+		if errors.Is(err, os.ErrNotExist) {
+			return ErrNoData
+		}
+		return fmt.Errorf("failed to retrieve nfsd stats: %w", err)
+	}
+	ch <- prometheus.MustNewConstMetric(c.serverRPCs, prometheus.CounterValue, float64(stats.ServerRPC.RPCCount))
+	ch <- prometheus.MustNewConstMetric(c.serverRPCErrors, prometheus.CounterValue, float64(stats.ServerRPC.BadFmt), "fmt")
+	ch <- prometheus.MustNewConstMetric(c.serverRPCErrors, prometheus.CounterValue, float64(stats.ServerRPC.BadAuth), "auth")
+	ch <- prometheus.MustNewConstMetric(c.serverRPCErrors, prometheus.CounterValue, float64(stats.ServerRPC.BadcInt), "cInt")
+	ch <- prometheus.MustNewConstMetric(c.serverReadBytes, prometheus.CounterValue, float64(stats.InputOutput.Read))
+	ch <- prometheus.MustNewConstMetric(c.serverWriteBytes, prometheus.CounterValue, float64(stats.InputOutput.Write))
+	sendNFSProcedures(ch, c.serverRequests, "2", stats.V2Stats)
+	sendNFSProcedures(ch, c.serverRequests, "3", stats.V3Stats)
+	sendNFSProcedures(ch, c.serverRequests, "4", stats.V4Ops)
+	return nil
+}
+
+// sendNFSProcedures sends a metric per procedure of a procfs nfs stats
+// struct, whose fields are the procedure counts.
+func sendNFSProcedures(ch chan<- prometheus.Metric, desc *prometheus.Desc, proto string, stats interface{}) {
+	v := reflect.ValueOf(stats)
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if field.Kind() != reflect.Uint64 {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue,
+			float64(field.Uint()), proto, v.Type().Field(i).Name)
+	}
+}
+
+func (c *nfsCollector) updateMounts(ch chan<- prometheus.Metric) error {
+	self, err := c.procFS.Self()
+	if err != nil {
+		return fmt.Errorf("failed to open /proc/self: %w", err)
+	}
+	mounts, err := self.MountStats()
+	if err != nil {
+		return fmt.Errorf("failed to parse mountstats: %w", err)
+	}
+
+	var found bool
+	// The same export can be mounted in several places, but the kernel
+	// reports the stats of the shared superblock for each of them. Mounts
+	// stacked on the same mountpoint have the same labels though, and only
+	// the first is kept so that no metric is sent twice.
+	seen := map[[3]string]bool{}
+	for _, m := range mounts {
+		stats, ok := m.Stats.(*procfs.MountStatsNFS)
+		if !ok {
+			continue
+		}
+		found = true
+		key := [3]string{m.Device, m.Mount, stats.Transport.Protocol}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		labels := key[:]
+
+		ch <- prometheus.MustNewConstMetric(c.mountAge, prometheus.GaugeValue, stats.Age.Seconds(), labels...)
+		ch <- prometheus.MustNewConstMetric(c.mountReadBytes, prometheus.CounterValue, float64(stats.Bytes.Read), labels...)
+		ch <- prometheus.MustNewConstMetric(c.mountWriteBytes, prometheus.CounterValue, float64(stats.Bytes.Write), labels...)
+		for _, op := range stats.Operations {
+			opLabels := append(labels[:len(labels):len(labels)], op.Operation)
+			ch <- prometheus.MustNewConstMetric(c.opRequests, prometheus.CounterValue, float64(op.Requests), opLabels...)
+			ch <- prometheus.MustNewConstMetric(c.opTransmissions, prometheus.CounterValue, float64(op.Transmissions), opLabels...)
+			ch <- prometheus.MustNewConstMetric(c.opMajorTimeouts, prometheus.CounterValue, float64(op.MajorTimeouts), opLabels...)
+			ch <- prometheus.MustNewConstMetric(c.opQueueTime, prometheus.CounterValue, float64(op.CumulativeQueueMilliseconds)/1000, opLabels...)
+			ch <- prometheus.MustNewConstMetric(c.opResponseTime, prometheus.CounterValue, float64(op.CumulativeTotalResponseMilliseconds)/1000, opLabels...)
+			ch <- prometheus.MustNewConstMetric(c.opRequestTime, prometheus.CounterValue, float64(op.CumulativeTotalRequestMilliseconds)/1000, opLabels...)
+			ch <- prometheus.MustNewConstMetric(c.opErrors, prometheus.CounterValue, float64(op.Errors), opLabels...)
+		}
+	}
+	if !found {
+		return ErrNoData
+	}
+	return nil
+}
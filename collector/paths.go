@@ -4,16 +4,24 @@ import (
 	"path/filepath"
 )
 
+// The mount points of the pseudo filesystems read by the collectors. They
+// are variables so that the collectors can be pointed at fixture trees.
+var (
+	procPath   = "/proc"
+	sysPath    = "/sys"
+	rootfsPath = "/"
+)
+
 func procFilePath(name string) string {
-	return filepath.Join("/proc", name)
+	return filepath.Join(procPath, name)
 }
 
 func sysFilePath(name string) string {
-	return filepath.Join("/sys", name)
+	return filepath.Join(sysPath, name)
 }
 
 func rootfsFilePath(name string) string {
-	return filepath.Join("/", name)
+	return filepath.Join(rootfsPath, name)
 }
 
 func rootfsStripPrefix(path string) string {
@@ -0,0 +1,233 @@
+package collector
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/procfs"
+)
+
+const (
+	processesSubsystem = "process"
+
+	// userHZ is the unit of the CPU times in /proc/[pid]/stat, as assumed
+	// by procfs.
+	userHZ = 100
+)
+
+var (
+	// ProcessesTopN is the number of processes exposed by the processes
+	// collector.
+	ProcessesTopN = 10
+	// ProcessesTopBy is the key the processes are ranked by, one of "cpu"
+	// (CPU usage), "memory" (resident memory), "io" (read and written
+	// bytes per second) and "fds" (open file descriptors).
+	ProcessesTopBy = "cpu"
+)
+
+var processesLabelNames = []string{"pid", "comm"}
+
+type processesCollector struct {
+	fs         procfs.FS
+	topN       int
+	topBy      string
+	cpuSeconds *prometheus.Desc
+	cpuUsage   *prometheus.Desc
+	rss        *prometheus.Desc
+	swap       *prometheus.Desc
+	threads    *prometheus.Desc
+	readBytes  *prometheus.Desc
+	writeBytes *prometheus.Desc
+	openFDs    *prometheus.Desc
+
+	mtx      sync.Mutex
+	previous map[processKey]processSample
+}
+
+// processKey identifies a process across updates; the start time tells apart
+// processes which reuse a PID.
+type processKey struct {
+	pid       int
+	startTime uint64
+}
+
+// processSample is the cumulative counters of a process at a point in time,
+// kept between updates to compute rates.
+type processSample struct {
+	cpuTime float64
+	ioBytes uint64
+	time    time.Time
+}
+
+// processInfo is what is known about a process in a single update.
+type processInfo struct {
+	proc     procfs.Proc
+	stat     procfs.ProcStat
+	io       *procfs.ProcIO
+	fds      int
+	cpuUsage float64
+	ioRate   float64
+}
+
+// NewProcessesCollector returns a new Collector exposing the resource usage
+// of the top-N processes.
+func NewProcessesCollector() (Collector, error) {
+	switch ProcessesTopBy {
+	case "cpu", "memory", "io", "fds":
+	default:
+		return nil, fmt.Errorf("invalid processes top-by key: %s", ProcessesTopBy)
+	}
+	if ProcessesTopN <= 0 {
+		return nil, fmt.Errorf("invalid processes top-n: %d", ProcessesTopN)
+	}
+	fs, err := procfs.NewFS(procPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open procfs: %w", err)
+	}
+
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, processesSubsystem, name),
+			help, processesLabelNames, nil,
+		)
+	}
+	return &processesCollector{
+		fs:         fs,
+		topN:       ProcessesTopN,
+		topBy:      ProcessesTopBy,
+		cpuSeconds: desc("cpu_seconds_total", "Total user and system CPU time spent by the process in seconds."),
+		cpuUsage:   desc("cpu_usage", "CPU usage of the process since the previous update, in percent of one CPU."),
+		rss:        desc("resident_memory_bytes", "Resident memory size of the process in bytes."),
+		swap:       desc("swap_bytes", "Swapped out memory of the process in bytes."),
+		threads:    desc("threads", "Number of threads of the process."),
+		readBytes:  desc("read_bytes_total", "Number of bytes the process read from storage."),
+		writeBytes: desc("write_bytes_total", "Number of bytes the process wrote to storage."),
+		openFDs:    desc("open_fds", "Number of open file descriptors of the process."),
+		previous:   map[processKey]processSample{},
+	}, nil
+}
+
+func (c *processesCollector) Update(ch chan<- prometheus.Metric) error {
+	procs, err := c.fs.AllProcs()
+	if err != nil {
+		return fmt.Errorf("couldn't list processes: %w", err)
+	}
+	stat, err := c.fs.Stat()
+	if err != nil {
+		return fmt.Errorf("couldn't get stat: %w", err)
+	}
+	now := time.Now()
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	infos := make([]*processInfo, 0, len(procs))
+	current := make(map[processKey]processSample, len(procs))
+	for _, proc := range procs {
+		procStat, err := proc.Stat()
+		if err != nil {
+			// The process exited since it was listed.
+			continue
+		}
+		info := &processInfo{proc: proc, stat: procStat}
+		// Reading io and fd of every process is expensive and often not
+		// permitted, so it is only done when ranking by it.
+		if c.topBy == "io" {
+			c.readIO(info)
+		}
+		if c.topBy == "fds" {
+			c.readFDs(info)
+		}
+
+		key := processKey{pid: proc.PID, startTime: procStat.Starttime}
+		sample := processSample{cpuTime: procStat.CPUTime(), time: now}
+		if info.io != nil {
+			sample.ioBytes = info.io.ReadBytes + info.io.WriteBytes
+		}
+		current[key] = sample
+
+		// Processes seen for the first time are rated over their lifetime.
+		prev, ok := c.previous[key]
+		if !ok {
+			startTime := float64(stat.BootTime) + float64(procStat.Starttime)/userHZ
+			prev = processSample{time: time.Unix(0, int64(startTime*float64(time.Second)))}
+		}
+		if elapsed := now.Sub(prev.time).Seconds(); elapsed > 0 {
+			info.cpuUsage = (sample.cpuTime - prev.cpuTime) / elapsed * 100
+			if sample.ioBytes >= prev.ioBytes {
+				info.ioRate = float64(sample.ioBytes-prev.ioBytes) / elapsed
+			}
+		}
+		infos = append(infos, info)
+	}
+	c.previous = current
+
+	sort.Slice(infos, func(i, j int) bool {
+		return c.rank(infos[i]) > c.rank(infos[j])
+	})
+	if len(infos) > c.topN {
+		infos = infos[:c.topN]
+	}
+
+	for _, info := range infos {
+		c.updateProcess(ch, info)
+	}
+	return nil
+}
+
+func (c *processesCollector) rank(info *processInfo) float64 {
+	switch c.topBy {
+	case "memory":
+		return float64(info.stat.ResidentMemory())
+	case "io":
+		return info.ioRate
+	case "fds":
+		return float64(info.fds)
+	default:
+		return info.cpuUsage
+	}
+}
+
+func (c *processesCollector) readIO(info *processInfo) {
+	if io, err := info.proc.IO(); err == nil {
+		info.io = &io
+	}
+}
+
+func (c *processesCollector) readFDs(info *processInfo) {
+	if fds, err := info.proc.FileDescriptorsLen(); err == nil {
+		info.fds = fds
+	} else {
+		info.fds = -1
+	}
+}
+
+func (c *processesCollector) updateProcess(ch chan<- prometheus.Metric, info *processInfo) {
+	labels := []string{strconv.Itoa(info.proc.PID), info.stat.Comm}
+
+	ch <- prometheus.MustNewConstMetric(c.cpuSeconds, prometheus.CounterValue, info.stat.CPUTime(), labels...)
+	ch <- prometheus.MustNewConstMetric(c.cpuUsage, prometheus.GaugeValue, info.cpuUsage, labels...)
+	ch <- prometheus.MustNewConstMetric(c.rss, prometheus.GaugeValue, float64(info.stat.ResidentMemory()), labels...)
+	ch <- prometheus.MustNewConstMetric(c.threads, prometheus.GaugeValue, float64(info.stat.NumThreads), labels...)
+
+	if status, err := info.proc.NewStatus(); err == nil {
+		ch <- prometheus.MustNewConstMetric(c.swap, prometheus.GaugeValue, float64(status.VmSwap), labels...)
+	}
+	if info.io == nil {
+		c.readIO(info)
+	}
+	if info.io != nil {
+		ch <- prometheus.MustNewConstMetric(c.readBytes, prometheus.CounterValue, float64(info.io.ReadBytes), labels...)
+		ch <- prometheus.MustNewConstMetric(c.writeBytes, prometheus.CounterValue, float64(info.io.WriteBytes), labels...)
+	}
+	if c.topBy != "fds" {
+		c.readFDs(info)
+	}
+	if info.fds >= 0 {
+		ch <- prometheus.MustNewConstMetric(c.openFDs, prometheus.GaugeValue, float64(info.fds), labels...)
+	}
+}
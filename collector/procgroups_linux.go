@@ -0,0 +1,217 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/procfs"
+)
+
+const (
+	procGroupsSubsystem = "processgroup"
+)
+
+// ProcessGroup selects the processes of a named group for the procgroups
+// collector. Every pattern which is set must match; a process is counted
+// in the first group it matches.
+type ProcessGroup struct {
+	Name string
+	// Comm is a regexp matched against the process name.
+	Comm string
+	// Exe is a regexp matched against the path of the executable.
+	Exe string
+	// Cmdline is a regexp matched against the space separated command line.
+	Cmdline string
+}
+
+// ProcessGroups is the list of groups exposed by the procgroups collector.
+var ProcessGroups []ProcessGroup
+
+type processGroupMatcher struct {
+	name                     string
+	commRE, exeRE, cmdlineRE *regexp.Regexp
+}
+
+// processGroupStats is the aggregate of the processes of a group.
+type processGroupStats struct {
+	procs, threads, openFDs int
+	rss, vsize              float64
+	oldestStartTime         float64
+}
+
+type procGroupsCollector struct {
+	fs       procfs.FS
+	matchers []processGroupMatcher
+
+	numProcs        *prometheus.Desc
+	cpuSeconds      *prometheus.Desc
+	rss             *prometheus.Desc
+	vsize           *prometheus.Desc
+	threads         *prometheus.Desc
+	openFDs         *prometheus.Desc
+	oldestStartTime *prometheus.Desc
+
+	mtx sync.Mutex
+	// cpuTime is the accumulated CPU time of every group, which keeps
+	// growing when processes exit.
+	cpuTime  map[string]float64
+	previous map[processKey]float64
+}
+
+// NewProcGroupsCollector returns a new Collector exposing the aggregated
+// resource usage of named process groups.
+func NewProcGroupsCollector() (Collector, error) {
+	matchers := make([]processGroupMatcher, 0, len(ProcessGroups))
+	seen := map[string]bool{}
+	for _, group := range ProcessGroups {
+		if group.Name == "" {
+			return nil, errors.New("process group without name")
+		}
+		if seen[group.Name] {
+			return nil, fmt.Errorf("duplicate process group: %s", group.Name)
+		}
+		seen[group.Name] = true
+		if group.Comm == "" && group.Exe == "" && group.Cmdline == "" {
+			return nil, fmt.Errorf("process group %s has no pattern", group.Name)
+		}
+		m := processGroupMatcher{name: group.Name}
+		for _, p := range []struct {
+			pattern string
+			re      **regexp.Regexp
+		}{
+			{group.Comm, &m.commRE},
+			{group.Exe, &m.exeRE},
+			{group.Cmdline, &m.cmdlineRE},
+		} {
+			if p.pattern == "" {
+				continue
+			}
+			re, err := regexp.Compile(p.pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern of process group %s: %w", group.Name, err)
+			}
+			*p.re = re
+		}
+		matchers = append(matchers, m)
+	}
+
+	fs, err := procfs.NewFS(procPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open procfs: %w", err)
+	}
+
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, procGroupsSubsystem, name),
+			help, []string{"group"}, nil,
+		)
+	}
+	return &procGroupsCollector{
+		fs:              fs,
+		matchers:        matchers,
+		numProcs:        desc("num_procs", "Number of processes in the group."),
+		cpuSeconds:      desc("cpu_seconds_total", "CPU time spent by the processes of the group in seconds."),
+		rss:             desc("resident_memory_bytes", "Resident memory of the processes of the group in bytes."),
+		vsize:           desc("virtual_memory_bytes", "Virtual memory of the processes of the group in bytes."),
+		threads:         desc("threads", "Number of threads of the processes of the group."),
+		openFDs:         desc("open_fds", "Number of open file descriptors of the processes of the group."),
+		oldestStartTime: desc("oldest_start_time_seconds", "Start time of the oldest process of the group since unix epoch in seconds, 0 if there is none."),
+		cpuTime:         map[string]float64{},
+		previous:        map[processKey]float64{},
+	}, nil
+}
+
+func (c *procGroupsCollector) Update(ch chan<- prometheus.Metric) error {
+	if len(c.matchers) == 0 {
+		return ErrNoData
+	}
+	procs, err := c.fs.AllProcs()
+	if err != nil {
+		return fmt.Errorf("couldn't list processes: %w", err)
+	}
+	stat, err := c.fs.Stat()
+	if err != nil {
+		return fmt.Errorf("couldn't get stat: %w", err)
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	groups := make(map[string]*processGroupStats, len(c.matchers))
+	for _, m := range c.matchers {
+		groups[m.name] = &processGroupStats{}
+	}
+	current := map[processKey]float64{}
+	for _, proc := range procs {
+		procStat, err := proc.Stat()
+		if err != nil {
+			// The process exited since it was listed.
+			continue
+		}
+		group := c.match(proc, procStat)
+		if group == "" {
+			continue
+		}
+
+		key := processKey{pid: proc.PID, startTime: procStat.Starttime}
+		cpuTime := procStat.CPUTime()
+		current[key] = cpuTime
+		c.cpuTime[group] += cpuTime - c.previous[key]
+
+		g := groups[group]
+		g.procs++
+		g.threads += procStat.NumThreads
+		g.rss += float64(procStat.ResidentMemory())
+		g.vsize += float64(procStat.VirtualMemory())
+		if fds, err := proc.FileDescriptorsLen(); err == nil {
+			g.openFDs += fds
+		}
+		startTime := float64(stat.BootTime) + float64(procStat.Starttime)/userHZ
+		if g.oldestStartTime == 0 || startTime < g.oldestStartTime {
+			g.oldestStartTime = startTime
+		}
+	}
+	c.previous = current
+
+	// Every configured group is sent, so that a group without processes
+	// shows up with num_procs 0.
+	for _, m := range c.matchers {
+		g := groups[m.name]
+		ch <- prometheus.MustNewConstMetric(c.numProcs, prometheus.GaugeValue, float64(g.procs), m.name)
+		ch <- prometheus.MustNewConstMetric(c.cpuSeconds, prometheus.CounterValue, c.cpuTime[m.name], m.name)
+		ch <- prometheus.MustNewConstMetric(c.rss, prometheus.GaugeValue, g.rss, m.name)
+		ch <- prometheus.MustNewConstMetric(c.vsize, prometheus.GaugeValue, g.vsize, m.name)
+		ch <- prometheus.MustNewConstMetric(c.threads, prometheus.GaugeValue, float64(g.threads), m.name)
+		ch <- prometheus.MustNewConstMetric(c.openFDs, prometheus.GaugeValue, float64(g.openFDs), m.name)
+		ch <- prometheus.MustNewConstMetric(c.oldestStartTime, prometheus.GaugeValue, g.oldestStartTime, m.name)
+	}
+	return nil
+}
+
+// match returns the name of the first group the process belongs to, or ""
+// if it belongs to none.
+func (c *procGroupsCollector) match(proc procfs.Proc, procStat procfs.ProcStat) string {
+	for _, m := range c.matchers {
+		if m.commRE != nil && !m.commRE.MatchString(procStat.Comm) {
+			continue
+		}
+		if m.exeRE != nil {
+			exe, err := proc.Executable()
+			if err != nil || !m.exeRE.MatchString(exe) {
+				continue
+			}
+		}
+		if m.cmdlineRE != nil {
+			cmdline, err := proc.CmdLine()
+			if err != nil || !m.cmdlineRE.MatchString(strings.Join(cmdline, " ")) {
+				continue
+			}
+		}
+		return m.name
+	}
+	return ""
+}
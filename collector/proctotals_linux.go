@@ -0,0 +1,144 @@
+package collector
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/procfs"
+)
+
+const (
+	procTotalsSubsystem = "procs"
+)
+
+type procTotalsCollector struct {
+	fs          procfs.FS
+	pids        *prometheus.Desc
+	procs       *prometheus.Desc
+	states      *prometheus.Desc
+	threads     *prometheus.Desc
+	threadsMax  *prometheus.Desc
+	pidMax      *prometheus.Desc
+	fdAllocated *prometheus.Desc
+	fdMax       *prometheus.Desc
+}
+
+// NewProcTotalsCollector returns a new Collector exposing host-wide process,
+// thread and file handle totals.
+func NewProcTotalsCollector() (Collector, error) {
+	fs, err := procfs.NewFS(procPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open procfs: %w", err)
+	}
+	desc := func(name, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, procTotalsSubsystem, name),
+			help, labels, nil,
+		)
+	}
+	return &procTotalsCollector{
+		fs:          fs,
+		pids:        desc("pids", "Number of PIDs in use, one per thread of every process, from loadavg, to compare with pid_max."),
+		procs:       desc("processes", "Number of processes."),
+		states:      desc("state", "Number of processes in each state (R running, S sleeping, D uninterruptible, Z zombie, ...).", "state"),
+		threads:     desc("threads", "Number of threads of all processes."),
+		threadsMax:  desc("threads_max", "Limit of the number of threads, from kernel/threads-max."),
+		pidMax:      desc("pid_max", "Largest PID plus one, from kernel/pid_max."),
+		fdAllocated: desc("filefd_allocated", "Number of allocated file handles."),
+		fdMax:       desc("filefd_maximum", "Limit of the number of file handles."),
+	}, nil
+}
+
+func (c *procTotalsCollector) Update(ch chan<- prometheus.Metric) error {
+	procs, err := c.fs.AllProcs()
+	if err != nil {
+		return fmt.Errorf("couldn't list processes: %w", err)
+	}
+	var processes, threads int
+	states := map[string]int{
+		"R": 0, "S": 0, "D": 0, "Z": 0, "T": 0, "I": 0,
+	}
+	for _, proc := range procs {
+		procStat, err := proc.Stat()
+		if err != nil {
+			// The process exited since it was listed.
+			continue
+		}
+		processes++
+		threads += procStat.NumThreads
+		states[procStat.State]++
+	}
+	ch <- prometheus.MustNewConstMetric(c.procs, prometheus.GaugeValue, float64(processes))
+	ch <- prometheus.MustNewConstMetric(c.threads, prometheus.GaugeValue, float64(threads))
+	for state, count := range states {
+		ch <- prometheus.MustNewConstMetric(c.states, prometheus.GaugeValue, float64(count), state)
+	}
+
+	pids, err := getPIDsInUse()
+	if err != nil {
+		return fmt.Errorf("couldn't get loadavg: %w", err)
+	}
+	ch <- prometheus.MustNewConstMetric(c.pids, prometheus.GaugeValue, float64(pids))
+
+	threadsMax, err := readUintFromFile(procFilePath("sys/kernel/threads-max"))
+	if err != nil {
+		return fmt.Errorf("couldn't get threads-max: %w", err)
+	}
+	ch <- prometheus.MustNewConstMetric(c.threadsMax, prometheus.GaugeValue, float64(threadsMax))
+
+	pidMax, err := readUintFromFile(procFilePath("sys/kernel/pid_max"))
+	if err != nil {
+		return fmt.Errorf("couldn't get pid_max: %w", err)
+	}
+	ch <- prometheus.MustNewConstMetric(c.pidMax, prometheus.GaugeValue, float64(pidMax))
+
+	allocated, maximum, err := getFileNr()
+	if err != nil {
+		return fmt.Errorf("couldn't get file-nr: %w", err)
+	}
+	ch <- prometheus.MustNewConstMetric(c.fdAllocated, prometheus.GaugeValue, float64(allocated))
+	ch <- prometheus.MustNewConstMetric(c.fdMax, prometheus.GaugeValue, float64(maximum))
+	return nil
+}
+
+// getPIDsInUse returns the number of tasks from /proc/loadavg, which holds
+// "<load1> <load5> <load15> <running>/<tasks> <last pid>". Every thread has
+// its own PID, the process ID being its main thread's.
+func getPIDsInUse() (uint64, error) {
+	data, err := ioutil.ReadFile(procFilePath("loadavg"))
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) != 5 {
+		return 0, fmt.Errorf("invalid loadavg: %q", data)
+	}
+	_, tasks, ok := strings.Cut(fields[3], "/")
+	if !ok {
+		return 0, fmt.Errorf("invalid loadavg: %q", data)
+	}
+	return strconv.ParseUint(tasks, 10, 64)
+}
+
+// getFileNr returns the allocated and maximum file handles from
+// /proc/sys/fs/file-nr, which holds "<allocated> <unused> <maximum>".
+func getFileNr() (allocated, maximum uint64, err error) {
+	data, err := ioutil.ReadFile(procFilePath("sys/fs/file-nr"))
+	if err != nil {
+		return 0, 0, err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) != 3 {
+		return 0, 0, fmt.Errorf("invalid file-nr: %q", data)
+	}
+	if allocated, err = strconv.ParseUint(fields[0], 10, 64); err != nil {
+		return 0, 0, err
+	}
+	if maximum, err = strconv.ParseUint(fields[2], 10, 64); err != nil {
+		return 0, 0, err
+	}
+	return allocated, maximum, nil
+}
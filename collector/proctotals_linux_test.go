@@ -0,0 +1,31 @@
+package collector
+
+import "testing"
+
+func TestProcTotalsCollector(t *testing.T) {
+	withFixtures(t)
+	c, err := NewProcTotalsCollector()
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics := collectMetrics(t, c)
+
+	for name, want := range map[string]float64{
+		// loadavg counts the tasks of the whole host, not only those of the
+		// listed processes: systemd with 1 thread and java with 57.
+		`propush_procs_pids`:             61,
+		`propush_procs_processes`:        2,
+		`propush_procs_threads`:          58,
+		`propush_procs_state{state="S"}`: 1,
+		`propush_procs_state{state="R"}`: 1,
+		`propush_procs_state{state="Z"}`: 0,
+		`propush_procs_threads_max`:      126382,
+		`propush_procs_pid_max`:          4194304,
+		`propush_procs_filefd_allocated`: 2048,
+		`propush_procs_filefd_maximum`:   9223372036854775807,
+	} {
+		if got, ok := metrics[name]; !ok || got != want {
+			t.Errorf("%s = %v (present %v), want %v", name, got, ok, want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/procfs"
+)
+
+const (
+	schedstatSubsystem = "schedstat"
+
+	nsPerSec = 1e9
+)
+
+var (
+	runningSecondsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, schedstatSubsystem, "running_seconds_total"),
+		"Number of seconds CPU spent running a process.",
+		[]string{"cpu"},
+		nil,
+	)
+	waitingSecondsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, schedstatSubsystem, "waiting_seconds_total"),
+		"Number of seconds spent by processing waiting for this CPU.",
+		[]string{"cpu"},
+		nil,
+	)
+	timeslicesTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, schedstatSubsystem, "timeslices_total"),
+		"Number of timeslices executed by CPU.",
+		[]string{"cpu"},
+		nil,
+	)
+)
+
+type schedstatCollector struct {
+	fs procfs.FS
+}
+
+// NewSchedstatCollector returns a new Collector exposing per-CPU scheduler
+// stats.
+func NewSchedstatCollector() (Collector, error) {
+	fs, err := procfs.NewFS(procPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open procfs: %w", err)
+	}
+	return &schedstatCollector{fs: fs}, nil
+}
+
+func (c *schedstatCollector) Update(ch chan<- prometheus.Metric) error {
+	stats, err := c.fs.Schedstat()
+	if err != nil {
+		// The kernel was built without CONFIG_SCHEDSTATS.
+		if errors.Is(err, os.ErrNotExist) {
+			return ErrNoData
+		}
+		return err
+	}
+
+	for _, cpu := range stats.CPUs {
+		ch <- prometheus.MustNewConstMetric(
+			runningSecondsTotal,
+			prometheus.CounterValue,
+			float64(cpu.RunningNanoseconds)/nsPerSec,
+			cpu.CPUNum,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			waitingSecondsTotal,
+			prometheus.CounterValue,
+			float64(cpu.WaitingNanoseconds)/nsPerSec,
+			cpu.CPUNum,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			timeslicesTotal,
+			prometheus.CounterValue,
+			float64(cpu.RunTimeslices),
+			cpu.CPUNum,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package collector
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	sockStatSubsystem = "sockstat"
+)
+
+// tcpStates maps the hexadecimal "st" column of /proc/net/tcp{,6} to the
+// names of the states in include/net/tcp_states.h.
+var tcpStates = []string{
+	0x01: "established",
+	0x02: "syn_sent",
+	0x03: "syn_recv",
+	0x04: "fin_wait1",
+	0x05: "fin_wait2",
+	0x06: "time_wait",
+	0x07: "close",
+	0x08: "close_wait",
+	0x09: "last_ack",
+	0x0A: "listen",
+	0x0B: "closing",
+	0x0C: "new_syn_recv",
+}
+
+type sockStatCollector struct {
+	tcpConnectionsDesc *prometheus.Desc
+}
+
+// NewSockStatCollector returns a new Collector exposing socket stats.
+func NewSockStatCollector() (Collector, error) {
+	return &sockStatCollector{
+		tcpConnectionsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, sockStatSubsystem, "tcp_connections"),
+			"Number of TCP sockets by address family and state.",
+			[]string{"family", "state"}, nil,
+		),
+	}, nil
+}
+
+func (c *sockStatCollector) Update(ch chan<- prometheus.Metric) error {
+	for _, family := range []struct{ name, file string }{
+		{"ipv4", "net/tcp"},
+		{"ipv6", "net/tcp6"},
+	} {
+		counts, err := getTCPStates(procFilePath(family.file))
+		if err != nil {
+			// On systems with IPv6 disabled, net/tcp6 won't exist.
+			if os.IsNotExist(err) {
+				continue
+			}
+			return fmt.Errorf("couldn't get %s stats: %w", family.file, err)
+		}
+		for st, state := range tcpStates {
+			if state == "" {
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(
+				c.tcpConnectionsDesc, prometheus.GaugeValue,
+				float64(counts[st]), family.name, state,
+			)
+		}
+	}
+
+	for _, file := range []string{"net/sockstat", "net/sockstat6"} {
+		sockStats, err := getSockStats(procFilePath(file))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return fmt.Errorf("couldn't get %s stats: %w", file, err)
+		}
+		for protocol, protocolStats := range sockStats {
+			for name, value := range protocolStats {
+				v, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					return fmt.Errorf("invalid value %s in %s: %w", value, file, err)
+				}
+				help := fmt.Sprintf("Number of %s sockets in state %s.", protocol, name)
+				// mem is counted in pages; additionally expose it in bytes.
+				if name == "mem" {
+					c.sendSockStat(ch, protocol, "mem_bytes", fmt.Sprintf("Memory used by %s sockets in bytes.", protocol), v*float64(os.Getpagesize()))
+					help = fmt.Sprintf("Memory used by %s sockets in pages.", protocol)
+				}
+				c.sendSockStat(ch, protocol, name, help, v)
+			}
+		}
+	}
+	return nil
+}
+
+func (c *sockStatCollector) sendSockStat(ch chan<- prometheus.Metric, protocol, name, help string, value float64) {
+	ch <- prometheus.MustNewConstMetric(
+		prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, sockStatSubsystem, protocol+"_"+name),
+			help,
+			nil, nil,
+		),
+		prometheus.GaugeValue, value,
+	)
+}
+
+func getTCPStates(fileName string) ([]uint64, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parseTCPStates(file)
+}
+
+// parseTCPStates counts the sockets of a /proc/net/tcp{,6} table by state.
+// Only the "st" column is looked at and lines are not split into fields,
+// so that hosts with hundreds of thousands of sockets stay cheap to scan.
+func parseTCPStates(r io.Reader) ([]uint64, error) {
+	counts := make([]uint64, len(tcpStates))
+	scanner := bufio.NewScanner(r)
+	scanner.Scan() // skip header
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		// "  sl  local_address rem_address   st ..."; st is the 4th field.
+		field, rest := 0, bytes.TrimLeft(line, " ")
+		for field < 3 && len(rest) > 0 {
+			i := bytes.IndexByte(rest, ' ')
+			if i < 0 {
+				rest = nil
+				break
+			}
+			rest = bytes.TrimLeft(rest[i:], " ")
+			field++
+		}
+		if len(rest) < 2 {
+			return nil, fmt.Errorf("invalid line in tcp table: %q", line)
+		}
+		st, ok := parseHexByte(rest[0], rest[1])
+		if !ok {
+			return nil, fmt.Errorf("invalid state in tcp table: %q", line)
+		}
+		if int(st) < len(counts) {
+			counts[st]++
+		}
+	}
+	return counts, scanner.Err()
+}
+
+func parseHexByte(hi, lo byte) (byte, bool) {
+	h, ok := fromHexChar(hi)
+	if !ok {
+		return 0, false
+	}
+	l, ok := fromHexChar(lo)
+	if !ok {
+		return 0, false
+	}
+	return h<<4 | l, true
+}
+
+func fromHexChar(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
+func getSockStats(fileName string) (map[string]map[string]string, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parseSockStats(file, fileName)
+}
+
+// parseSockStats parses lines like "TCP: inuse 4 orphan 0 tw 0 alloc 4 mem 0"
+// into a map of protocol to field values.
+func parseSockStats(r io.Reader, fileName string) (map[string]map[string]string, error) {
+	var (
+		sockStats = map[string]map[string]string{}
+		scanner   = bufio.NewScanner(r)
+	)
+
+	for scanner.Scan() {
+		line := strings.Fields(scanner.Text())
+		if len(line) < 3 || len(line)%2 != 1 {
+			return nil, fmt.Errorf("invalid line in %s: %q", fileName, scanner.Text())
+		}
+		protocol := strings.TrimSuffix(line[0], ":")
+		sockStats[protocol] = map[string]string{}
+		for i := 1; i < len(line); i += 2 {
+			sockStats[protocol][line[i]] = line[i+1]
+		}
+	}
+
+	return sockStats, scanner.Err()
+}
@@ -0,0 +1,158 @@
+package collector
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTCPStates(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		table   string
+		want    map[string]uint64
+		wantErr bool
+	}{
+		{
+			name:  "header only",
+			table: "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n",
+			want:  map[string]uint64{},
+		},
+		{
+			name: "ipv4",
+			table: "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n" +
+				"   0: 0100007F:BC8F 00000000:0000 0A 00000000:00000000 00:00000000 00000000 65534        0 928 1\n" +
+				"   1: 0A00020F:0016 0A000201:C350 01 00000000:00000000 02:0008E1B2 00000000     0        0 1514 2\n" +
+				"  10: 0A00020F:0016 0A000201:C351 0b 00000000:00000000 03:000017A6 00000000     0        0 0 3\n",
+			want: map[string]uint64{"listen": 1, "established": 1, "closing": 1},
+		},
+		{
+			name: "ipv6",
+			table: "  sl  local_address                         remote_address                        st tx_queue rx_queue\n" +
+				"   0: 00000000000000000000000000000000:0016 00000000000000000000000000000000:0000 0A 00000000:00000000\n" +
+				"   1: 0000000000000000FFFF00000F02000A:0016 0000000000000000FFFF00000102000A:C352 06 00000000:00000000\n" +
+				"   2: 0000000000000000FFFF00000F02000A:0016 0000000000000000FFFF00000102000A:C353 06 00000000:00000000\n",
+			want: map[string]uint64{"listen": 1, "time_wait": 2},
+		},
+		{
+			name: "unknown state",
+			table: "header\n" +
+				"   0: 0100007F:BC8F 00000000:0000 FF 00000000:00000000\n",
+			want: map[string]uint64{},
+		},
+		{
+			name:    "invalid state",
+			table:   "header\n   0: 0100007F:BC8F 00000000:0000 ZZ 00000000:00000000\n",
+			wantErr: true,
+		},
+		{
+			name:    "truncated line",
+			table:   "header\n   0: 0100007F:BC8F\n",
+			wantErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			counts, err := parseTCPStates(strings.NewReader(tc.table))
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := map[string]uint64{}
+			for st, count := range counts {
+				if count > 0 {
+					got[tcpStates[st]] = count
+				}
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseSockStats(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		file    string
+		want    map[string]map[string]string
+		wantErr bool
+	}{
+		{
+			name: "sockstat",
+			file: "sockets: used 18\nTCP: inuse 4 orphan 0 tw 1 alloc 6 mem 3\nFRAG: inuse 0 memory 0\n",
+			want: map[string]map[string]string{
+				"sockets": {"used": "18"},
+				"TCP":     {"inuse": "4", "orphan": "0", "tw": "1", "alloc": "6", "mem": "3"},
+				"FRAG":    {"inuse": "0", "memory": "0"},
+			},
+		},
+		{
+			name: "sockstat6",
+			file: "TCP6: inuse 3\nUDP6: inuse 1\n",
+			want: map[string]map[string]string{
+				"TCP6": {"inuse": "3"},
+				"UDP6": {"inuse": "1"},
+			},
+		},
+		{
+			name:    "missing value",
+			file:    "TCP: inuse 4 orphan\n",
+			wantErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseSockStats(strings.NewReader(tc.file), tc.name)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSockStatCollector(t *testing.T) {
+	withFixtures(t)
+	c, err := NewSockStatCollector()
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics := collectMetrics(t, c)
+
+	for name, want := range map[string]float64{
+		`propush_sockstat_tcp_connections{family="ipv4",state="listen"}`:      2,
+		`propush_sockstat_tcp_connections{family="ipv4",state="established"}`: 1,
+		`propush_sockstat_tcp_connections{family="ipv4",state="time_wait"}`:   1,
+		`propush_sockstat_tcp_connections{family="ipv4",state="close_wait"}`:  0,
+		`propush_sockstat_tcp_connections{family="ipv6",state="listen"}`:      1,
+		`propush_sockstat_tcp_connections{family="ipv6",state="established"}`: 1,
+		`propush_sockstat_tcp_connections{family="ipv6",state="close_wait"}`:  1,
+		`propush_sockstat_sockets_used`:                                       18,
+		`propush_sockstat_TCP_inuse`:                                          4,
+		`propush_sockstat_TCP_mem`:                                            3,
+		`propush_sockstat_TCP_mem_bytes`:                                      3 * float64(os.Getpagesize()),
+		`propush_sockstat_TCP6_inuse`:                                         3,
+	} {
+		got, ok := metrics[name]
+		if !ok {
+			t.Errorf("missing metric %s", name)
+			continue
+		}
+		if got != want {
+			t.Errorf("%s = %v, want %v", name, got, want)
+		}
+	}
+}
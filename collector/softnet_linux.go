@@ -0,0 +1,185 @@
+package collector
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	softnetSubsystem = "softnet"
+)
+
+type softnetCollector struct {
+	processed    *prometheus.Desc
+	dropped      *prometheus.Desc
+	timeSqueezed *prometheus.Desc
+	backlogLen   *prometheus.Desc
+	softirqs     *prometheus.Desc
+}
+
+// softnetStat is a line of /proc/net/softnet_stat.
+type softnetStat struct {
+	cpu          string
+	processed    uint64
+	dropped      uint64
+	timeSqueezed uint64
+	// backlogLen is only reported since Linux 5.10.
+	backlogLen    uint64
+	hasBacklogLen bool
+}
+
+// NewSoftnetCollector returns a new Collector exposing softnet and network
+// softirq stats.
+func NewSoftnetCollector() (Collector, error) {
+	return &softnetCollector{
+		processed: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, softnetSubsystem, "processed_total"),
+			"Number of processed packets.",
+			[]string{"cpu"}, nil,
+		),
+		dropped: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, softnetSubsystem, "dropped_total"),
+			"Number of dropped packets.",
+			[]string{"cpu"}, nil,
+		),
+		timeSqueezed: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, softnetSubsystem, "times_squeezed_total"),
+			"Number of times processing packets ran out of quota.",
+			[]string{"cpu"}, nil,
+		),
+		backlogLen: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, softnetSubsystem, "backlog_len"),
+			"Softnet backlog status.",
+			[]string{"cpu"}, nil,
+		),
+		softirqs: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, softnetSubsystem, "softirqs_total"),
+			"Number of network softirqs handled.",
+			[]string{"cpu", "type"}, nil,
+		),
+	}, nil
+}
+
+func (c *softnetCollector) Update(ch chan<- prometheus.Metric) error {
+	stats, err := getSoftnetStats()
+	if err != nil {
+		return fmt.Errorf("couldn't get softnet statistics: %w", err)
+	}
+	for _, stat := range stats {
+		ch <- prometheus.MustNewConstMetric(c.processed, prometheus.CounterValue, float64(stat.processed), stat.cpu)
+		ch <- prometheus.MustNewConstMetric(c.dropped, prometheus.CounterValue, float64(stat.dropped), stat.cpu)
+		ch <- prometheus.MustNewConstMetric(c.timeSqueezed, prometheus.CounterValue, float64(stat.timeSqueezed), stat.cpu)
+		if stat.hasBacklogLen {
+			ch <- prometheus.MustNewConstMetric(c.backlogLen, prometheus.GaugeValue, float64(stat.backlogLen), stat.cpu)
+		}
+	}
+
+	cpus, softirqs, err := getSoftirqs()
+	if err != nil {
+		return fmt.Errorf("couldn't get softirqs: %w", err)
+	}
+	for _, name := range []string{"NET_RX", "NET_TX"} {
+		for i, v := range softirqs[name] {
+			ch <- prometheus.MustNewConstMetric(c.softirqs, prometheus.CounterValue, float64(v), cpus[i], name)
+		}
+	}
+	return nil
+}
+
+func getSoftnetStats() ([]softnetStat, error) {
+	file, err := os.Open(procFilePath("net/softnet_stat"))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parseSoftnetStats(file)
+}
+
+// parseSoftnetStats parses /proc/net/softnet_stat, which has one line of
+// hexadecimal columns per online CPU. Since Linux 5.10 the 13th column holds
+// the CPU number; before that the line number is used.
+func parseSoftnetStats(r io.Reader) ([]softnetStat, error) {
+	var (
+		stats   []softnetStat
+		scanner = bufio.NewScanner(r)
+	)
+
+	for line := 0; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 9 {
+			return nil, fmt.Errorf("invalid line in softnet_stat: %q", scanner.Text())
+		}
+		values := make([]uint64, len(fields))
+		for i, field := range fields {
+			v, err := strconv.ParseUint(field, 16, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %s in softnet_stat: %w", field, err)
+			}
+			values[i] = v
+		}
+		stat := softnetStat{
+			cpu:          strconv.Itoa(line),
+			processed:    values[0],
+			dropped:      values[1],
+			timeSqueezed: values[2],
+		}
+		if len(values) >= 13 {
+			stat.backlogLen = values[11]
+			stat.hasBacklogLen = true
+			stat.cpu = strconv.FormatUint(values[12], 10)
+		}
+		stats = append(stats, stat)
+	}
+
+	return stats, scanner.Err()
+}
+
+func getSoftirqs() ([]string, map[string][]uint64, error) {
+	file, err := os.Open(procFilePath("softirqs"))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	return parseSoftirqs(file)
+}
+
+// parseSoftirqs parses /proc/softirqs into the CPU numbers of its columns and
+// the per-CPU counts of every softirq type.
+func parseSoftirqs(r io.Reader) ([]string, map[string][]uint64, error) {
+	scanner := bufio.NewScanner(r)
+	if !scanner.Scan() {
+		return nil, nil, fmt.Errorf("softirqs empty")
+	}
+	cpus := strings.Fields(scanner.Text())
+	for i, cpu := range cpus {
+		cpus[i] = strings.TrimPrefix(cpu, "CPU")
+	}
+
+	softirqs := map[string][]uint64{}
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != len(cpus)+1 {
+			return nil, nil, fmt.Errorf("invalid line in softirqs: %q", scanner.Text())
+		}
+		name := strings.TrimSuffix(fields[0], ":")
+		values := make([]uint64, len(cpus))
+		for i, field := range fields[1:] {
+			v, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid value %s in softirqs: %w", field, err)
+			}
+			values[i] = v
+		}
+		softirqs[name] = values
+	}
+
+	return cpus, softirqs, scanner.Err()
+}
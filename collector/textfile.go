@@ -0,0 +1,201 @@
+package collector
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// TextfileDirectory is the directory the textfile collector reads *.prom
+// files in the Prometheus text format from.
+var TextfileDirectory string
+
+var (
+	textfileMtimeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "textfile", "mtime_seconds"),
+		"Unixtime mtime of textfiles successfully read.",
+		[]string{"file"},
+		nil,
+	)
+	textfileErrorDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "textfile", "scrape_error"),
+		"1 if there was an error opening or reading a file, 0 otherwise.",
+		[]string{"file"},
+		nil,
+	)
+)
+
+type textFileCollector struct {
+	path string
+}
+
+// NewTextFileCollector returns a new Collector exposing metrics read from
+// files in a directory.
+func NewTextFileCollector() (Collector, error) {
+	return &textFileCollector{
+		path: TextfileDirectory,
+	}, nil
+}
+
+func (c *textFileCollector) Update(ch chan<- prometheus.Metric) error {
+	if c.path == "" {
+		return ErrNoData
+	}
+	files, err := ioutil.ReadDir(c.path)
+	if err != nil {
+		return fmt.Errorf("failed to read textfile collector directory %q: %w", c.path, err)
+	}
+
+	// Families are merged across files, so that a metric may be split over
+	// several files as long as help and type agree.
+	families := map[string]*dto.MetricFamily{}
+	var failed bool
+	for _, f := range files {
+		if !strings.HasSuffix(f.Name(), ".prom") {
+			continue
+		}
+		path := filepath.Join(c.path, f.Name())
+		mtime, err := c.processFile(path, families)
+		if err != nil {
+			failed = true
+			ch <- prometheus.MustNewConstMetric(textfileErrorDesc, prometheus.GaugeValue, 1, path)
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(textfileErrorDesc, prometheus.GaugeValue, 0, path)
+		ch <- prometheus.MustNewConstMetric(textfileMtimeDesc, prometheus.GaugeValue, mtime, path)
+	}
+
+	for _, mf := range families {
+		convertMetricFamily(mf, ch)
+	}
+	if failed {
+		return fmt.Errorf("failed to read some textfiles in %q", c.path)
+	}
+	return nil
+}
+
+// processFile parses a file into families and returns its mtime. Nothing is
+// merged from a file which fails to parse or conflicts with other files.
+func (c *textFileCollector) processFile(path string, families map[string]*dto.MetricFamily) (float64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var parser expfmt.TextParser
+	parsed, err := parser.TextToMetricFamilies(f)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse textfile data from %q: %w", path, err)
+	}
+
+	for name, mf := range parsed {
+		for _, m := range mf.Metric {
+			if m.TimestampMs != nil {
+				return 0, fmt.Errorf("textfile %q contains unsupported client-side timestamps", path)
+			}
+		}
+		if existing, ok := families[name]; ok {
+			if existing.GetType() != mf.GetType() || existing.GetHelp() != mf.GetHelp() {
+				return 0, fmt.Errorf("textfile %q has metric %s with help or type inconsistent with other files", path, name)
+			}
+		}
+	}
+	for name, mf := range parsed {
+		if existing, ok := families[name]; ok {
+			existing.Metric = append(existing.Metric, mf.Metric...)
+			continue
+		}
+		families[name] = mf
+	}
+
+	// Stat the file only after parsing it, so that the reported mtime is
+	// never older than the data which was read.
+	stat, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return float64(stat.ModTime().UnixNano()) / 1e9, nil
+}
+
+// convertMetricFamily sends the metrics of a parsed family as const metrics.
+// Label names missing from some of the metrics are filled with empty values,
+// as every metric of a Desc must have the same labels.
+func convertMetricFamily(mf *dto.MetricFamily, ch chan<- prometheus.Metric) {
+	var valType prometheus.ValueType
+	var val float64
+
+	allLabelNames := map[string]struct{}{}
+	for _, m := range mf.Metric {
+		for _, label := range m.GetLabel() {
+			allLabelNames[label.GetName()] = struct{}{}
+		}
+	}
+	names := make([]string, 0, len(allLabelNames))
+	for name := range allLabelNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, metric := range mf.Metric {
+		labels := metric.GetLabel()
+		values := make([]string, len(names))
+		for i, name := range names {
+			for _, label := range labels {
+				if label.GetName() == name {
+					values[i] = label.GetValue()
+					break
+				}
+			}
+		}
+
+		switch mf.GetType() {
+		case dto.MetricType_COUNTER:
+			valType = prometheus.CounterValue
+			val = metric.Counter.GetValue()
+		case dto.MetricType_GAUGE:
+			valType = prometheus.GaugeValue
+			val = metric.Gauge.GetValue()
+		case dto.MetricType_UNTYPED:
+			valType = prometheus.UntypedValue
+			val = metric.Untyped.GetValue()
+		case dto.MetricType_SUMMARY:
+			quantiles := map[float64]float64{}
+			for _, q := range metric.Summary.Quantile {
+				quantiles[q.GetQuantile()] = q.GetValue()
+			}
+			ch <- prometheus.MustNewConstSummary(
+				prometheus.NewDesc(*mf.Name, mf.GetHelp(), names, nil),
+				metric.Summary.GetSampleCount(),
+				metric.Summary.GetSampleSum(),
+				quantiles, values...,
+			)
+			continue
+		case dto.MetricType_HISTOGRAM:
+			buckets := map[float64]uint64{}
+			for _, b := range metric.Histogram.Bucket {
+				buckets[b.GetUpperBound()] = b.GetCumulativeCount()
+			}
+			ch <- prometheus.MustNewConstHistogram(
+				prometheus.NewDesc(*mf.Name, mf.GetHelp(), names, nil),
+				metric.Histogram.GetSampleCount(),
+				metric.Histogram.GetSampleSum(),
+				buckets, values...,
+			)
+			continue
+		default:
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc(*mf.Name, mf.GetHelp(), names, nil),
+			valType, val, values...,
+		)
+	}
+}
@@ -0,0 +1,81 @@
+package collector
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	timexSubsystem = "timex"
+
+	// The frequency is in ppm with a 16-bit fractional part.
+	ppm16frac = 1000000.0 * 65536.0
+
+	microSeconds = 1000000.0
+	nanoSeconds  = 1000000000.0
+)
+
+type timexCollector struct {
+	offset, freq, maxerror, esterror, status, constant, tick, tai, syncStatus, now typedDesc
+}
+
+// NewTimexCollector returns a new Collector exposing the kernel clock
+// discipline state from adjtimex(2).
+func NewTimexCollector() (Collector, error) {
+	desc := func(name, help string) typedDesc {
+		return typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, timexSubsystem, name),
+			help, nil, nil,
+		), prometheus.GaugeValue}
+	}
+	return &timexCollector{
+		offset:     desc("offset_seconds", "Time offset in between local system and reference clock."),
+		freq:       desc("frequency_adjustment_ratio", "Local clock frequency adjustment."),
+		maxerror:   desc("maxerror_seconds", "Maximum error in seconds."),
+		esterror:   desc("estimated_error_seconds", "Estimated error in seconds."),
+		status:     desc("status", "Value of the status array bits."),
+		constant:   desc("loop_time_constant", "Phase-locked loop time constant."),
+		tick:       desc("tick_seconds", "Seconds between clock ticks."),
+		tai:        desc("tai_offset_seconds", "International Atomic Time (TAI) offset."),
+		syncStatus: desc("sync_status", "Is clock synchronized to a reliable server (1 = yes, 0 = no)."),
+		now: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "time_seconds"),
+			"System time in seconds since epoch (1970).",
+			nil, nil,
+		), prometheus.GaugeValue},
+	}, nil
+}
+
+func (c *timexCollector) Update(ch chan<- prometheus.Metric) error {
+	ch <- c.now.mustNewConstMetric(float64(time.Now().UnixNano()) / nanoSeconds)
+
+	// Modes 0 only reads the state.
+	timex := new(unix.Timex)
+	state, err := unix.Adjtimex(timex)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve adjtimex stats: %w", err)
+	}
+
+	var syncStatus float64
+	if state != unix.TIME_ERROR {
+		syncStatus = 1
+	}
+	divisor := microSeconds
+	if timex.Status&unix.STA_NANO != 0 {
+		divisor = nanoSeconds
+	}
+
+	ch <- c.syncStatus.mustNewConstMetric(syncStatus)
+	ch <- c.offset.mustNewConstMetric(float64(timex.Offset) / divisor)
+	ch <- c.freq.mustNewConstMetric(1 + float64(timex.Freq)/ppm16frac)
+	ch <- c.maxerror.mustNewConstMetric(float64(timex.Maxerror) / microSeconds)
+	ch <- c.esterror.mustNewConstMetric(float64(timex.Esterror) / microSeconds)
+	ch <- c.status.mustNewConstMetric(float64(timex.Status))
+	ch <- c.constant.mustNewConstMetric(float64(timex.Constant))
+	ch <- c.tick.mustNewConstMetric(float64(timex.Tick) / microSeconds)
+	ch <- c.tai.mustNewConstMetric(float64(timex.Tai))
+	return nil
+}
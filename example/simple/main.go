@@ -8,6 +8,10 @@ import (
 
 func main() {
 	ins := collector.GetInstance()
+	if ins == nil {
+		fmt.Println("GetInstance err:", collector.InstanceError())
+		return
+	}
 	fmt.Println(ins.GetMetrics())
 	err := ins.PushMetrics("http://127.0.0.1:9091", ins.GetMetrics())
 	if err != nil {
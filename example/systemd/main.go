@@ -6,24 +6,43 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/binacs/ProPush/collector"
 )
 
-var job, instance, endpoint string
+var job, instance, endpoint, enableCollectors, disableCollectors string
 
 func init() {
 	flag.StringVar(&job, "job", "defaultJobName", "Job name")
 	flag.StringVar(&instance, "instance", "defaultInstanceName", "Instance name")
 	flag.StringVar(&endpoint, "endpoint", "http://127.0.0.1:9091", "Push gateway endpoints")
+	flag.StringVar(&enableCollectors, "collectors.enable", "", "Comma separated list of collectors to enable")
+	flag.StringVar(&disableCollectors, "collectors.disable", "", "Comma separated list of collectors to disable")
+	flag.StringVar(&collector.NetDevDeviceExclude, "netdev.device-exclude", "", "Regexp of net devices to exclude (mutually exclusive to netdev.device-include)")
+	flag.StringVar(&collector.NetDevDeviceInclude, "netdev.device-include", "", "Regexp of net devices to include (mutually exclusive to netdev.device-exclude)")
+	flag.BoolVar(&collector.NetDevNetlink, "netdev.netlink", false, "Use rtnetlink instead of /proc/net/dev to read net device stats")
+	flag.StringVar(&collector.NetStatFields, "netstat.fields", collector.NetStatFields, "Regexp of fields to return for netstat collector")
+	flag.IntVar(&collector.ProcessesTopN, "processes.top-n", collector.ProcessesTopN, "Number of processes to return for processes collector")
+	flag.StringVar(&collector.ProcessesTopBy, "processes.top-by", collector.ProcessesTopBy, "Key to rank processes by: cpu, memory, io or fds")
+	flag.Var(processGroupsFlag{}, "procgroups.group", "Process group of the procgroups collector as name=comm-regexp, may be repeated")
+	flag.StringVar(&collector.InterruptsInclude, "interrupts.include", "", "Regexp of IRQs to include (mutually exclusive to interrupts.exclude)")
+	flag.StringVar(&collector.InterruptsExclude, "interrupts.exclude", "", "Regexp of IRQs to exclude (mutually exclusive to interrupts.include)")
+	flag.BoolVar(&collector.InterruptsAggregateCPUs, "interrupts.aggregate-cpus", false, "Sum interrupts over all CPUs")
+	flag.StringVar(&collector.TextfileDirectory, "textfile.directory", "", "Directory to read text files with metrics from")
 }
 
 func main() {
 	flag.Parse()
 
+	setCollectorStates(enableCollectors, true)
+	setCollectorStates(disableCollectors, false)
 	ins := collector.GetInstance()
+	if ins == nil {
+		log.Fatalln("GetInstance err:", collector.InstanceError())
+	}
 	ins.SetJob(job)
 	hostname, err := os.Hostname();
 	if err != nil {
@@ -51,6 +70,34 @@ func main() {
 	RunForever()
 }
 
+func setCollectorStates(names string, enabled bool) {
+	if names == "" {
+		return
+	}
+	for _, name := range strings.Split(names, ",") {
+		if err := collector.SetCollectorState(strings.TrimSpace(name), enabled); err != nil {
+			log.Fatalln("SetCollectorState err:", err)
+		}
+	}
+}
+
+// processGroupsFlag appends the process groups given as name=comm-regexp to
+// collector.ProcessGroups.
+type processGroupsFlag struct{}
+
+func (processGroupsFlag) String() string {
+	return ""
+}
+
+func (processGroupsFlag) Set(value string) error {
+	name, comm, ok := strings.Cut(value, "=")
+	if !ok || name == "" || comm == "" {
+		return fmt.Errorf("invalid process group %q, want name=comm-regexp", value)
+	}
+	collector.ProcessGroups = append(collector.ProcessGroups, collector.ProcessGroup{Name: name, Comm: comm})
+	return nil
+}
+
 func RunForever() {
 	TrapSignal(func() {
 	})
@@ -1,9 +1,10 @@
 module github.com/binacs/ProPush
 
-go 1.19
+go 1.20
 
 require (
 	github.com/prometheus/client_golang v1.14.0
+	github.com/prometheus/client_model v0.3.0
 	github.com/prometheus/common v0.42.0
 	github.com/prometheus/procfs v0.10.1
 	golang.org/x/sys v0.8.0
@@ -14,6 +15,5 @@ require (
 	github.com/cespare/xxhash/v2 v2.1.2 // indirect
 	github.com/golang/protobuf v1.5.2 // indirect
 	github.com/matttproud/golang_protobuf_extensions v1.0.4 // indirect
-	github.com/prometheus/client_model v0.3.0 // indirect
 	google.golang.org/protobuf v1.28.1 // indirect
 )